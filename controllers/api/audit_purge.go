@@ -0,0 +1,119 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	log "github.com/gophish/gophish/logger"
+	"github.com/gophish/gophish/models"
+	"github.com/gorilla/mux"
+)
+
+// AuditPurgeSchedule handles retrieving, creating, and updating the
+// runtime-configurable schedule for the audit-log retention job.
+func (as *Server) AuditPurgeSchedule(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		schedule, err := models.GetPurgeSchedule(models.PurgeScheduleKindAuditLog)
+		if err != nil {
+			JSONResponse(w, models.Response{Success: false, Message: "No schedule configured"}, http.StatusNotFound)
+			return
+		}
+		JSONResponse(w, schedule, http.StatusOK)
+
+	case "POST", "PUT":
+		var req purgeScheduleRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			JSONResponse(w, models.Response{Success: false, Message: "Invalid JSON structure"}, http.StatusBadRequest)
+			return
+		}
+
+		schedule, err := models.SavePurgeSchedule(models.PurgeScheduleKindAuditLog, req.Type, req.CronExpr, req.Parameters)
+		if err != nil {
+			log.Errorf("Error saving audit purge schedule: %v", err)
+			JSONResponse(w, models.Response{Success: false, Message: err.Error()}, http.StatusBadRequest)
+			return
+		}
+
+		if as.auditPurgeJob != nil {
+			as.auditPurgeJob.Reload()
+		}
+
+		JSONResponse(w, schedule, http.StatusOK)
+
+	default:
+		JSONResponse(w, models.Response{Success: false, Message: "Method not allowed"}, http.StatusMethodNotAllowed)
+	}
+}
+
+// AuditPurgeRun manually triggers the audit-log retention job and returns
+// the resulting execution ID immediately.
+func (as *Server) AuditPurgeRun(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		JSONResponse(w, models.Response{Success: false, Message: "Method not allowed"}, http.StatusMethodNotAllowed)
+		return
+	}
+
+	if as.auditPurgeJob == nil {
+		JSONResponse(w, models.Response{Success: false, Message: "Audit purge job is not configured"}, http.StatusServiceUnavailable)
+		return
+	}
+
+	executionID, err := as.auditPurgeJob.TriggerManual()
+	if err != nil {
+		log.Errorf("Error triggering manual audit purge: %v", err)
+		JSONResponse(w, models.Response{Success: false, Message: "Error triggering audit purge"}, http.StatusInternalServerError)
+		return
+	}
+	JSONResponse(w, map[string]interface{}{"id": executionID}, http.StatusAccepted)
+}
+
+// AuditPurgeExecutions lists audit purge execution history, paginated and
+// optionally filtered by status.
+func (as *Server) AuditPurgeExecutions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		JSONResponse(w, models.Response{Success: false, Message: "Method not allowed"}, http.StatusMethodNotAllowed)
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if perPage < 1 || perPage > 100 {
+		perPage = 50
+	}
+	offset := (page - 1) * perPage
+
+	execs, total, err := models.GetAuditPurgeExecutionsPaginated(status, offset, perPage)
+	if err != nil {
+		log.Errorf("Error listing audit purge executions: %v", err)
+		JSONResponse(w, models.Response{Success: false, Message: "Error listing audit purge executions"}, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+	JSONResponse(w, execs, http.StatusOK)
+}
+
+// AuditPurgeExecution returns a single audit purge execution record.
+func (as *Server) AuditPurgeExecution(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		JSONResponse(w, models.Response{Success: false, Message: "Method not allowed"}, http.StatusMethodNotAllowed)
+		return
+	}
+
+	vars := mux.Vars(r)
+	id, _ := strconv.ParseInt(vars["id"], 0, 64)
+
+	exec, err := models.GetAuditPurgeExecution(id)
+	if err != nil {
+		JSONResponse(w, models.Response{Success: false, Message: "Execution not found"}, http.StatusNotFound)
+		return
+	}
+	JSONResponse(w, exec, http.StatusOK)
+}
@@ -74,28 +74,28 @@ func (as *Server) Campaign(w http.ResponseWriter, r *http.Request) {
 	case r.Method == "GET":
 		JSONResponse(w, c, http.StatusOK)
 	case r.Method == "DELETE":
-		// Soft delete - move to trash
-		// Read optional reason from body
+		// Soft delete - move to trash. Read optional reason from body.
 		var req struct {
 			Reason string `json:"reason"`
 		}
 		json.NewDecoder(r.Body).Decode(&req)
 
-		err = models.SoftDeleteCampaign(id, ctx.Get(r, "user_id").(int64), req.Reason)
+		userID := ctx.Get(r, "user_id").(int64)
+		if c.UserId != userID {
+			JSONResponse(w, models.Response{Success: false, Message: "Permission denied"}, http.StatusForbidden)
+			return
+		}
+
+		job, err := as.jobRunner.Enqueue("delete", id, func() (map[string]interface{}, error) {
+			return nil, models.SoftDeleteCampaign(id, userID, req.Reason)
+		})
 		if err != nil {
-			if err == models.ErrCampaignNotFound {
-				JSONResponse(w, models.Response{Success: false, Message: "Campaign not found"}, http.StatusNotFound)
-				return
-			}
-			if err == models.ErrPermissionDenied {
-				JSONResponse(w, models.Response{Success: false, Message: "Permission denied"}, http.StatusForbidden)
-				return
-			}
-			log.Errorf("Error soft deleting campaign %d: %v", id, err)
+			log.Errorf("Error enqueueing delete job for campaign %d: %v", id, err)
 			JSONResponse(w, models.Response{Success: false, Message: "Error moving campaign to trash"}, http.StatusInternalServerError)
 			return
 		}
-		JSONResponse(w, models.Response{Success: true, Message: "Campaign moved to trash"}, http.StatusOK)
+
+		respondJobAccepted(w, job)
 	}
 }
 
@@ -189,7 +189,10 @@ func (as *Server) CampaignsTrash(w http.ResponseWriter, r *http.Request) {
 	JSONResponse(w, response, http.StatusOK)
 }
 
-// CampaignRestore restores a campaign from trash
+// CampaignRestore restores a campaign from trash. Restoring can involve
+// re-checking name conflicts across a user's whole campaign list, so rather
+// than block the request, this enqueues a models.Job and responds
+// 202 Accepted with a Location the UI can poll for the outcome.
 func (as *Server) CampaignRestore(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
 		JSONResponse(w, models.Response{Success: false, Message: "Method not allowed"}, http.StatusMethodNotAllowed)
@@ -200,37 +203,47 @@ func (as *Server) CampaignRestore(w http.ResponseWriter, r *http.Request) {
 	id, _ := strconv.ParseInt(vars["id"], 0, 64)
 	userID := ctx.Get(r, "user_id").(int64)
 
-	result, err := models.RestoreCampaign(id, userID)
+	// Cheap synchronous checks so a missing/forbidden/not-trashed campaign
+	// fails the request immediately instead of surfacing only as a polled
+	// job error, mirroring CampaignPurge below.
+	c, err := models.GetCampaign(id, userID)
 	if err != nil {
-		if err == models.ErrCampaignNotFound {
-			JSONResponse(w, models.Response{Success: false, Message: "Campaign not found"}, http.StatusNotFound)
-			return
-		}
-		if err == models.ErrNotDeleted {
-			JSONResponse(w, models.Response{Success: false, Message: "Campaign is not in trash"}, http.StatusBadRequest)
-			return
-		}
-		if err == models.ErrPermissionDenied {
-			JSONResponse(w, models.Response{Success: false, Message: "Permission denied"}, http.StatusForbidden)
-			return
-		}
-		log.Errorf("Error restoring campaign %d: %v", id, err)
-		JSONResponse(w, models.Response{Success: false, Message: "Error restoring campaign"}, http.StatusInternalServerError)
+		JSONResponse(w, models.Response{Success: false, Message: "Campaign not found"}, http.StatusNotFound)
+		return
+	}
+	if c.UserId != userID {
+		JSONResponse(w, models.Response{Success: false, Message: "Permission denied"}, http.StatusForbidden)
+		return
+	}
+	if !c.IsDeleted() {
+		JSONResponse(w, models.Response{Success: false, Message: "Campaign is not in trash"}, http.StatusBadRequest)
 		return
 	}
 
-	response := map[string]interface{}{
-		"success":      true,
-		"message":      "Campaign restored successfully",
-		"campaign":     result.Campaign,
-		"warnings":     result.Warnings,
-		"name_changed": result.NameChanged,
+	job, err := as.jobRunner.Enqueue("restore", id, func() (map[string]interface{}, error) {
+		result, err := models.RestoreCampaign(id, userID)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"campaign_id":  result.Campaign.Id,
+			"warnings":     result.Warnings,
+			"name_changed": result.NameChanged,
+		}, nil
+	})
+	if err != nil {
+		log.Errorf("Error enqueueing restore job for campaign %d: %v", id, err)
+		JSONResponse(w, models.Response{Success: false, Message: "Error restoring campaign"}, http.StatusInternalServerError)
+		return
 	}
 
-	JSONResponse(w, response, http.StatusOK)
+	respondJobAccepted(w, job)
 }
 
-// CampaignPurge permanently deletes a campaign (hard delete)
+// CampaignPurge permanently deletes a campaign (hard delete). The delete can
+// take a while on campaigns with thousands of results, so the actual purge
+// runs as a models.Job; this handler only does the cheap synchronous checks
+// (admin privilege, confirmation match) before enqueueing it.
 func (as *Server) CampaignPurge(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "DELETE" {
 		JSONResponse(w, models.Response{Success: false, Message: "Method not allowed"}, http.StatusMethodNotAllowed)
@@ -241,23 +254,14 @@ func (as *Server) CampaignPurge(w http.ResponseWriter, r *http.Request) {
 	id, _ := strconv.ParseInt(vars["id"], 0, 64)
 	userID := ctx.Get(r, "user_id").(int64)
 
-	// Check if user is admin
-	user, err := models.GetUser(userID)
-	if err != nil {
-		log.Errorf("Error getting user %d: %v", userID, err)
-		JSONResponse(w, models.Response{Success: false, Message: "Error verifying permissions"}, http.StatusInternalServerError)
-		return
-	}
-
-	isAdmin := user.Role.Slug == "admin"
-	if !isAdmin {
-		JSONResponse(w, models.Response{Success: false, Message: "Admin privileges required"}, http.StatusForbidden)
+	if !as.requireAdmin(w, userID) {
 		return
 	}
 
 	// Read confirmation from body
 	var req struct {
 		Confirmation string `json:"confirmation"`
+		Force        bool   `json:"force"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		JSONResponse(w, models.Response{Success: false, Message: "Invalid request body"}, http.StatusBadRequest)
@@ -277,13 +281,53 @@ func (as *Server) CampaignPurge(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Purge
-	err = models.PurgeCampaign(id, userID, true)
+	job, err := as.jobRunner.Enqueue("purge", id, func() (map[string]interface{}, error) {
+		return nil, models.PurgeCampaign(id, userID, true, req.Force)
+	})
 	if err != nil {
-		log.Errorf("Error purging campaign %d: %v", id, err)
-		JSONResponse(w, models.Response{Success: false, Message: err.Error()}, http.StatusInternalServerError)
+		log.Errorf("Error enqueueing purge job for campaign %d: %v", id, err)
+		JSONResponse(w, models.Response{Success: false, Message: "Error purging campaign"}, http.StatusInternalServerError)
+		return
+	}
+
+	respondJobAccepted(w, job)
+}
+
+// CampaignLegalHold places or releases a legal hold on a trashed (or
+// not-yet-trashed) campaign, blocking TTL auto-purge and user-initiated
+// purge until released or overridden with force. Restricted to admins.
+func (as *Server) CampaignLegalHold(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, _ := strconv.ParseInt(vars["id"], 0, 64)
+	userID := ctx.Get(r, "user_id").(int64)
+
+	if !as.requireAdmin(w, userID) {
 		return
 	}
 
-	JSONResponse(w, models.Response{Success: true, Message: "Campaign permanently deleted"}, http.StatusOK)
+	switch r.Method {
+	case "POST":
+		var req struct {
+			Reason string `json:"reason"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			JSONResponse(w, models.Response{Success: false, Message: "Invalid request body"}, http.StatusBadRequest)
+			return
+		}
+		if err := models.PlaceLegalHold(id, userID, req.Reason, true); err != nil {
+			log.Errorf("Error placing legal hold on campaign %d: %v", id, err)
+			JSONResponse(w, models.Response{Success: false, Message: "Error placing legal hold"}, http.StatusInternalServerError)
+			return
+		}
+		JSONResponse(w, models.Response{Success: true, Message: "Legal hold placed"}, http.StatusOK)
+	case "DELETE":
+		if err := models.ReleaseLegalHold(id, userID, true); err != nil {
+			log.Errorf("Error releasing legal hold on campaign %d: %v", id, err)
+			JSONResponse(w, models.Response{Success: false, Message: "Error releasing legal hold"}, http.StatusInternalServerError)
+			return
+		}
+		JSONResponse(w, models.Response{Success: true, Message: "Legal hold released"}, http.StatusOK)
+	default:
+		JSONResponse(w, models.Response{Success: false, Message: "Method not allowed"}, http.StatusMethodNotAllowed)
+	}
 }
@@ -0,0 +1,202 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	ctx "github.com/gophish/gophish/context"
+	log "github.com/gophish/gophish/logger"
+	"github.com/gophish/gophish/models"
+)
+
+// bulkTrashRequest is the JSON body accepted by the bulk restore/purge
+// endpoints: either an explicit list of campaign ids, or all=true (with an
+// optional age filter) to select every matching trashed campaign.
+type bulkTrashRequest struct {
+	IDs           []int64 `json:"ids"`
+	All           bool    `json:"all"`
+	OlderThanDays int     `json:"older_than_days"`
+	Confirmation  string  `json:"confirmation"`
+}
+
+// bulkPurgeConfirmation is the confirmation string a bulk purge or
+// empty-trash request must echo back, mirroring CampaignPurge's per-campaign
+// confirmation but at the batch level since there's no single campaign name
+// to match against.
+const bulkPurgeConfirmation = "DELETE ALL"
+
+// bulkTrashResultPayload converts a models.BulkTrashResult into the job
+// result map clients poll for via GET /api/jobs/{id}.
+func bulkTrashResultPayload(result *models.BulkTrashResult) map[string]interface{} {
+	return map[string]interface{}{
+		"batch_id":  result.BatchID,
+		"succeeded": result.Succeeded,
+		"failed":    result.Failed,
+	}
+}
+
+// decodeBulkTrashRequest decodes the common ids/all shape shared by the
+// restore and purge bulk endpoints. It doesn't validate the result; callers
+// check that ids or all=true was given.
+func decodeBulkTrashRequest(r *http.Request) (*bulkTrashRequest, error) {
+	var req bulkTrashRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// CampaignsTrashRestore bulk-restores either the given campaign ids or
+// every trashed campaign older than older_than_days (when all=true),
+// enqueued as a single models.Job so the request doesn't block for the
+// duration of the batch. See CampaignRestore for the single-campaign form.
+func (as *Server) CampaignsTrashRestore(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		JSONResponse(w, models.Response{Success: false, Message: "Method not allowed"}, http.StatusMethodNotAllowed)
+		return
+	}
+
+	req, err := decodeBulkTrashRequest(r)
+	if err != nil {
+		JSONResponse(w, models.Response{Success: false, Message: "Invalid request body"}, http.StatusBadRequest)
+		return
+	}
+	if len(req.IDs) == 0 && !req.All {
+		JSONResponse(w, models.Response{Success: false, Message: "Must provide ids or all=true"}, http.StatusBadRequest)
+		return
+	}
+
+	userID := ctx.Get(r, "user_id").(int64)
+
+	job, err := as.jobRunner.Enqueue("bulk_restore", 0, func() (map[string]interface{}, error) {
+		result, err := models.BulkRestoreCampaigns(context.Background(), req.IDs, req.All, req.OlderThanDays, userID)
+		if err != nil {
+			return nil, err
+		}
+		return bulkTrashResultPayload(result), nil
+	})
+	if err != nil {
+		log.Errorf("Error enqueueing bulk restore job: %v", err)
+		JSONResponse(w, models.Response{Success: false, Message: "Error restoring campaigns"}, http.StatusInternalServerError)
+		return
+	}
+
+	respondJobAccepted(w, job)
+}
+
+// CampaignsTrashPurge bulk-purges either the given campaign ids or every
+// trashed campaign older than older_than_days (when all=true). Requires
+// admin privileges and the batch-level confirmation string, and refuses
+// up front if any id listed in ids is currently CampaignInProgress (an
+// all=true request has no ids to check here).
+func (as *Server) CampaignsTrashPurge(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		JSONResponse(w, models.Response{Success: false, Message: "Method not allowed"}, http.StatusMethodNotAllowed)
+		return
+	}
+
+	req, err := decodeBulkTrashRequest(r)
+	if err != nil {
+		JSONResponse(w, models.Response{Success: false, Message: "Invalid request body"}, http.StatusBadRequest)
+		return
+	}
+	if len(req.IDs) == 0 && !req.All {
+		JSONResponse(w, models.Response{Success: false, Message: "Must provide ids or all=true"}, http.StatusBadRequest)
+		return
+	}
+
+	userID := ctx.Get(r, "user_id").(int64)
+	if !as.requireAdmin(w, userID) {
+		return
+	}
+	if req.Confirmation != bulkPurgeConfirmation {
+		JSONResponse(w, models.Response{Success: false, Message: "Confirmation does not match"}, http.StatusBadRequest)
+		return
+	}
+
+	inProgress, err := models.AnyCampaignInProgress(req.IDs)
+	if err != nil {
+		log.Errorf("Error checking in-progress campaigns before bulk purge: %v", err)
+		JSONResponse(w, models.Response{Success: false, Message: "Error verifying campaign status"}, http.StatusInternalServerError)
+		return
+	}
+	if inProgress {
+		JSONResponse(w, models.Response{Success: false, Message: "Cannot purge a campaign that is still in progress"}, http.StatusConflict)
+		return
+	}
+
+	job, err := as.jobRunner.Enqueue("bulk_purge", 0, func() (map[string]interface{}, error) {
+		result, err := models.BulkPurgeCampaigns(context.Background(), req.IDs, req.All, req.OlderThanDays, userID)
+		if err != nil {
+			return nil, err
+		}
+		return bulkTrashResultPayload(result), nil
+	})
+	if err != nil {
+		log.Errorf("Error enqueueing bulk purge job: %v", err)
+		JSONResponse(w, models.Response{Success: false, Message: "Error purging campaigns"}, http.StatusInternalServerError)
+		return
+	}
+
+	respondJobAccepted(w, job)
+}
+
+// CampaignsTrashEmpty is the "empty trash" shortcut: it purges every
+// trashed campaign, regardless of age, with the same admin/confirmation
+// requirements as CampaignsTrashPurge.
+func (as *Server) CampaignsTrashEmpty(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "DELETE" {
+		JSONResponse(w, models.Response{Success: false, Message: "Method not allowed"}, http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Confirmation string `json:"confirmation"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONResponse(w, models.Response{Success: false, Message: "Invalid request body"}, http.StatusBadRequest)
+		return
+	}
+
+	userID := ctx.Get(r, "user_id").(int64)
+	if !as.requireAdmin(w, userID) {
+		return
+	}
+	if req.Confirmation != bulkPurgeConfirmation {
+		JSONResponse(w, models.Response{Success: false, Message: "Confirmation does not match"}, http.StatusBadRequest)
+		return
+	}
+
+	job, err := as.jobRunner.Enqueue("empty_trash", 0, func() (map[string]interface{}, error) {
+		result, err := models.EmptyTrash(context.Background(), userID)
+		if err != nil {
+			return nil, err
+		}
+		return bulkTrashResultPayload(result), nil
+	})
+	if err != nil {
+		log.Errorf("Error enqueueing empty-trash job: %v", err)
+		JSONResponse(w, models.Response{Success: false, Message: "Error emptying trash"}, http.StatusInternalServerError)
+		return
+	}
+
+	respondJobAccepted(w, job)
+}
+
+// requireAdmin writes a 403 response and returns false when userID doesn't
+// belong to an admin. It is the shared admin check for CampaignPurge,
+// CampaignLegalHold and the bulk-purge endpoints.
+func (as *Server) requireAdmin(w http.ResponseWriter, userID int64) bool {
+	user, err := models.GetUser(userID)
+	if err != nil {
+		log.Errorf("Error getting user %d: %v", userID, err)
+		JSONResponse(w, models.Response{Success: false, Message: "Error verifying permissions"}, http.StatusInternalServerError)
+		return false
+	}
+	if user.Role.Slug != "admin" {
+		JSONResponse(w, models.Response{Success: false, Message: "Admin privileges required"}, http.StatusForbidden)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	log "github.com/gophish/gophish/logger"
+	"github.com/gophish/gophish/models"
+	"github.com/gorilla/mux"
+)
+
+// JobResponse is the polling-friendly representation of a models.Job,
+// returned by both the enqueueing endpoint (202 Accepted) and
+// GET /api/jobs/{id}.
+type JobResponse struct {
+	Id        string                 `json:"id"`
+	Type      string                 `json:"type"`
+	State     string                 `json:"state"`
+	Errors    []string               `json:"errors,omitempty"`
+	Result    map[string]interface{} `json:"result,omitempty"`
+	CreatedAt string                 `json:"created_at"`
+	UpdatedAt string                 `json:"updated_at"`
+	Links     map[string]string      `json:"links,omitempty"`
+}
+
+// jobPresenter builds the type-specific "links" section of a JobResponse.
+// Looking these up by the job's type prefix (rather than a switch baked
+// into the handler) is what lets new long-running operations plug in by
+// just registering a presenter, instead of editing the Job GET endpoint.
+type jobPresenter func(job *models.Job, result map[string]interface{}) map[string]string
+
+var jobPresenters = map[string]jobPresenter{
+	"purge": func(job *models.Job, result map[string]interface{}) map[string]string {
+		return nil
+	},
+	"delete": func(job *models.Job, result map[string]interface{}) map[string]string {
+		return nil
+	},
+	"restore": func(job *models.Job, result map[string]interface{}) map[string]string {
+		if campaignID, ok := result["campaign_id"]; ok {
+			return map[string]string{
+				"campaign": fmt.Sprintf("/api/campaigns/%v", campaignID),
+			}
+		}
+		return nil
+	},
+}
+
+// presentJob turns a models.Job into its API representation, dispatching
+// to the presenter registered for the job's typed id prefix.
+func presentJob(job *models.Job) (*JobResponse, error) {
+	errs, err := job.GetErrors()
+	if err != nil {
+		return nil, err
+	}
+	result, err := job.GetResult()
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &JobResponse{
+		Id:        job.Id,
+		Type:      job.Type,
+		State:     job.State,
+		Errors:    errs,
+		CreatedAt: job.CreatedAt.Format(http.TimeFormat),
+		UpdatedAt: job.UpdatedAt.Format(http.TimeFormat),
+	}
+
+	if job.State == models.JobStateComplete {
+		resp.Result = result
+		if presenter, ok := jobPresenters[jobTypePrefix(job.Id)]; ok {
+			resp.Links = presenter(job, result)
+		}
+	}
+
+	return resp, nil
+}
+
+// jobTypePrefix extracts the "purge"/"restore"/... prefix from a typed job
+// id like "purge.42.9f1c2b...".
+func jobTypePrefix(id string) string {
+	parts := strings.SplitN(id, ".", 2)
+	return parts[0]
+}
+
+// Job returns the current state of a single async job, for polling by the
+// UI after an endpoint responds 202 Accepted with a Location pointing here.
+func (as *Server) Job(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		JSONResponse(w, models.Response{Success: false, Message: "Method not allowed"}, http.StatusMethodNotAllowed)
+		return
+	}
+
+	vars := mux.Vars(r)
+	job, err := models.GetJob(vars["id"])
+	if err != nil {
+		JSONResponse(w, models.Response{Success: false, Message: "Job not found"}, http.StatusNotFound)
+		return
+	}
+
+	resp, err := presentJob(job)
+	if err != nil {
+		log.Errorf("Error presenting job %s: %v", job.Id, err)
+		JSONResponse(w, models.Response{Success: false, Message: "Error reading job"}, http.StatusInternalServerError)
+		return
+	}
+
+	JSONResponse(w, resp, http.StatusOK)
+}
+
+// respondJobAccepted writes the 202 Accepted response used by every
+// endpoint that now enqueues work instead of running it inline.
+func respondJobAccepted(w http.ResponseWriter, job *models.Job) {
+	resp, err := presentJob(job)
+	if err != nil {
+		log.Errorf("Error presenting job %s: %v", job.Id, err)
+		JSONResponse(w, models.Response{Success: false, Message: "Error enqueueing job"}, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/api/jobs/%s", job.Id))
+	JSONResponse(w, resp, http.StatusAccepted)
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	log "github.com/gophish/gophish/logger"
+	"github.com/gophish/gophish/models"
+)
+
+// purgeScheduleRequest is the JSON body accepted by POST/PUT
+// /api/trash/purge/schedule.
+type purgeScheduleRequest struct {
+	Type       string                 `json:"type"`
+	CronExpr   string                 `json:"cron_expr"`
+	Parameters map[string]interface{} `json:"parameters"`
+}
+
+// PurgeSchedule handles retrieving, creating, updating, and clearing the
+// runtime-configurable schedule for the campaign-trash TTL job.
+func (as *Server) PurgeSchedule(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		schedule, err := models.GetPurgeSchedule(models.PurgeScheduleKindTrash)
+		if err != nil {
+			JSONResponse(w, models.Response{Success: false, Message: "No schedule configured"}, http.StatusNotFound)
+			return
+		}
+		JSONResponse(w, schedule, http.StatusOK)
+
+	case "POST", "PUT":
+		var req purgeScheduleRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			JSONResponse(w, models.Response{Success: false, Message: "Invalid JSON structure"}, http.StatusBadRequest)
+			return
+		}
+
+		schedule, err := models.SavePurgeSchedule(models.PurgeScheduleKindTrash, req.Type, req.CronExpr, req.Parameters)
+		if err != nil {
+			log.Errorf("Error saving purge schedule: %v", err)
+			JSONResponse(w, models.Response{Success: false, Message: err.Error()}, http.StatusBadRequest)
+			return
+		}
+
+		if as.trashTTLJob != nil {
+			as.trashTTLJob.Reload()
+		}
+
+		JSONResponse(w, schedule, http.StatusOK)
+
+	case "DELETE":
+		if err := models.DeletePurgeSchedule(models.PurgeScheduleKindTrash); err != nil {
+			log.Errorf("Error deleting purge schedule: %v", err)
+			JSONResponse(w, models.Response{Success: false, Message: "Error deleting schedule"}, http.StatusInternalServerError)
+			return
+		}
+		if as.trashTTLJob != nil {
+			as.trashTTLJob.Reload()
+		}
+		JSONResponse(w, models.Response{Success: true, Message: "Schedule reverted to defaults"}, http.StatusOK)
+
+	default:
+		JSONResponse(w, models.Response{Success: false, Message: "Method not allowed"}, http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	log "github.com/gophish/gophish/logger"
+	"github.com/gophish/gophish/models"
+	"github.com/gorilla/mux"
+)
+
+// PurgeExecutions lists TrashTTLJob execution history if requested via GET,
+// or manually triggers a new purge run if requested via POST.
+func (as *Server) PurgeExecutions(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		status := r.URL.Query().Get("status")
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page < 1 {
+			page = 1
+		}
+		perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+		if perPage < 1 || perPage > 100 {
+			perPage = 50
+		}
+		offset := (page - 1) * perPage
+
+		execs, total, err := models.GetPurgeExecutionsPaginated(status, offset, perPage)
+		if err != nil {
+			log.Errorf("Error listing purge executions: %v", err)
+			JSONResponse(w, models.Response{Success: false, Message: "Error listing purge executions"}, http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+		JSONResponse(w, execs, http.StatusOK)
+	case "POST":
+		if as.trashTTLJob == nil {
+			JSONResponse(w, models.Response{Success: false, Message: "Trash TTL job is not configured"}, http.StatusServiceUnavailable)
+			return
+		}
+		executionID, err := as.trashTTLJob.TriggerManual()
+		if err != nil {
+			log.Errorf("Error triggering manual purge: %v", err)
+			JSONResponse(w, models.Response{Success: false, Message: "Error triggering purge"}, http.StatusInternalServerError)
+			return
+		}
+		JSONResponse(w, map[string]interface{}{"id": executionID}, http.StatusAccepted)
+	default:
+		JSONResponse(w, models.Response{Success: false, Message: "Method not allowed"}, http.StatusMethodNotAllowed)
+	}
+}
+
+// PurgeExecution returns a single purge execution record.
+func (as *Server) PurgeExecution(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		JSONResponse(w, models.Response{Success: false, Message: "Method not allowed"}, http.StatusMethodNotAllowed)
+		return
+	}
+
+	vars := mux.Vars(r)
+	id, _ := strconv.ParseInt(vars["id"], 0, 64)
+
+	exec, err := models.GetPurgeExecution(id)
+	if err != nil {
+		JSONResponse(w, models.Response{Success: false, Message: "Execution not found"}, http.StatusNotFound)
+		return
+	}
+	JSONResponse(w, exec, http.StatusOK)
+}
+
+// PurgeExecutionLog returns the combined log lines teed from a single purge
+// execution, so admins can see exactly what the TTL job did without
+// scraping process stdout.
+func (as *Server) PurgeExecutionLog(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		JSONResponse(w, models.Response{Success: false, Message: "Method not allowed"}, http.StatusMethodNotAllowed)
+		return
+	}
+
+	vars := mux.Vars(r)
+	id, _ := strconv.ParseInt(vars["id"], 0, 64)
+
+	lines, err := models.GetPurgeExecutionLog(id)
+	if err != nil {
+		JSONResponse(w, models.Response{Success: false, Message: "Execution not found"}, http.StatusNotFound)
+		return
+	}
+	JSONResponse(w, map[string]interface{}{"lines": lines}, http.StatusOK)
+}
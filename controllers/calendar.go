@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
 	"time"
 
@@ -198,6 +199,44 @@ func (ps *PhishingServer) CalendarTrack(w http.ResponseWriter, r *http.Request)
 	w.Write([]byte{0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00, 0x00, 0xff, 0xff, 0xff, 0x00, 0x00, 0x00, 0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x01, 0x00, 0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b})
 }
 
+// CalendarRSVP receives inbound iCalendar REPLY messages (relayed by an MX
+// webhook, or replayed from an IMAP poller) and correlates them back to the
+// Result that the invite was sent to.
+func (ps *PhishingServer) CalendarRSVP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.NotFound(w, r)
+		return
+	}
+
+	raw, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Errorf("CalendarRSVP: Error reading body: %v", err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	rsvp, err := models.ParseCalendarReply(raw)
+	if err != nil {
+		log.Errorf("CalendarRSVP: Error parsing REPLY: %v", err)
+		http.Error(w, "Unable to parse REPLY", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := models.HandleCalendarReply(rsvp); err != nil {
+		if err == models.ErrRSVPNoMatch {
+			// Not one of ours (or the UID is stale) - ack so the relay
+			// doesn't keep retrying delivery.
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		log.Errorf("CalendarRSVP: Error handling REPLY: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
 // CalendarDownloadICS serves the .ics file for download (for testing)
 func (ps *PhishingServer) CalendarDownloadICS(w http.ResponseWriter, r *http.Request) {
 	rid := r.URL.Query().Get("rid")
@@ -230,8 +269,12 @@ func (ps *PhishingServer) CalendarDownloadICS(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	// Serve as downloadable file
-	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	// Serve as downloadable file. Advertising method=REQUEST and the
+	// Content-Class header mirrors what the mailed invite sends, so a
+	// downloaded copy still opens straight into Outlook/Google Calendar's
+	// accept/decline flow instead of a plain text viewer.
+	w.Header().Set("Content-Type", "text/calendar; method=REQUEST; charset=utf-8")
+	w.Header().Set("Content-Class", "urn:content-classes:calendarmessage")
 	w.Header().Set("Content-Disposition", "attachment; filename=meeting.ics")
 	w.Write([]byte(icsContent))
 }
@@ -6,29 +6,84 @@ import (
 	"time"
 )
 
+// Attendee is a single mailbox/CN pair written to an ATTENDEE property. A
+// campaign's invite can carry more than one, e.g. to CC a shared mailbox
+// alongside the primary target.
+type Attendee struct {
+	Name  string
+	Email string
+}
+
 // CalendarEvent represents the data needed to generate an .ICS file
 type CalendarEvent struct {
-	UID             string
-	Title           string
-	Description     string
-	Location        string
-	StartTime       time.Time
-	EndTime         time.Time
-	OrganizerName   string
-	OrganizerEmail  string
-	AttendeeName    string
-	AttendeeEmail   string
+	UID            string
+	Title          string
+	Description    string
+	Location       string
+	StartTime      time.Time
+	EndTime        time.Time
+	OrganizerName  string
+	OrganizerEmail string
+	AttendeeName   string
+	AttendeeEmail  string
+	// Attendees, when set, takes precedence over AttendeeName/AttendeeEmail
+	// and is emitted as one ATTENDEE line per entry.
+	Attendees       []Attendee
 	ReminderMinutes int
 	MeetingURL      string
+	// Timezone is an IANA zone name (e.g. "America/New_York"). When set,
+	// DTSTART/DTEND/EXDATE are written with a TZID parameter instead of the
+	// floating UTC "Z" form, and a matching VTIMEZONE block is emitted so
+	// the client doesn't have to guess the offset. Empty keeps the existing
+	// UTC behavior.
+	Timezone string
+	// Method is the iTIP method written to the METHOD property. Defaults to
+	// REQUEST when empty, matching the value clients expect to auto-render
+	// an "Accept / Decline" invite.
+	Method string
+	// RecurrenceRule, when set, is written verbatim as the RRULE value
+	// (e.g. "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=6") so clients expand the
+	// recurrence natively instead of Gophish sending one invite per instance.
+	RecurrenceRule string
+	// ExceptionDates are emitted as EXDATE values, one per occurrence that
+	// should be skipped from the RecurrenceRule's expansion.
+	ExceptionDates []time.Time
+	// Sequence increments on each re-send of an invite for the same UID so
+	// Outlook treats the message as an update rather than a duplicate.
+	Sequence int
+	// RecurrenceID, when set, marks this VEVENT as a single-instance
+	// override of the recurring event sharing its UID (e.g. a one-off time
+	// change for a single occurrence) rather than the whole series.
+	RecurrenceID *time.Time
 }
 
 // Generate creates an .ICS file content from the CalendarEvent
 func (e *CalendarEvent) Generate() string {
-	// Format times in UTC for .ICS (YYYYMMDDTHHmmssZ)
-	startTime := e.StartTime.UTC().Format("20060102T150405Z")
-	endTime := e.EndTime.UTC().Format("20060102T150405Z")
+	loc := e.loadTimezone()
+
+	// Format times in the event's timezone when one was resolved, otherwise
+	// fall back to the existing floating-UTC "Z" form.
+	dtStartProp := "DTSTART"
+	dtEndProp := "DTEND"
+	exdateProp := "EXDATE"
+	var startTime, endTime string
+	if loc != nil {
+		dtStartProp = fmt.Sprintf("DTSTART;TZID=%s", e.Timezone)
+		dtEndProp = fmt.Sprintf("DTEND;TZID=%s", e.Timezone)
+		exdateProp = fmt.Sprintf("EXDATE;TZID=%s", e.Timezone)
+		startTime = e.StartTime.In(loc).Format("20060102T150405")
+		endTime = e.EndTime.In(loc).Format("20060102T150405")
+	} else {
+		startTime = e.StartTime.UTC().Format("20060102T150405Z")
+		endTime = e.EndTime.UTC().Format("20060102T150405Z")
+	}
 	timestamp := time.Now().UTC().Format("20060102T150405Z")
 
+	method := e.Method
+	if method == "" {
+		method = "REQUEST"
+	}
+
 	// Build description with meeting URL
 	description := e.Description
 	if e.MeetingURL != "" {
@@ -46,15 +101,40 @@ func (e *CalendarEvent) Generate() string {
 	ics.WriteString("BEGIN:VCALENDAR\r\n")
 	ics.WriteString("VERSION:2.0\r\n")
 	ics.WriteString("PRODID:-//Gophish//Calendar Phishing//EN\r\n")
-	ics.WriteString("METHOD:REQUEST\r\n")
+	ics.WriteString(fmt.Sprintf("METHOD:%s\r\n", method))
 	ics.WriteString("CALSCALE:GREGORIAN\r\n")
 
+	if loc != nil {
+		ics.WriteString(vtimezoneBlock(e.Timezone, loc, e.StartTime))
+	}
+
 	// VEVENT
 	ics.WriteString("BEGIN:VEVENT\r\n")
 	ics.WriteString(foldLine(fmt.Sprintf("UID:%s", e.UID)))
 	ics.WriteString(fmt.Sprintf("DTSTAMP:%s\r\n", timestamp))
-	ics.WriteString(fmt.Sprintf("DTSTART:%s\r\n", startTime))
-	ics.WriteString(fmt.Sprintf("DTEND:%s\r\n", endTime))
+	ics.WriteString(fmt.Sprintf("%s:%s\r\n", dtStartProp, startTime))
+	ics.WriteString(fmt.Sprintf("%s:%s\r\n", dtEndProp, endTime))
+	if e.RecurrenceID != nil {
+		recurrenceIDProp := "RECURRENCE-ID"
+		var recurrenceID string
+		if loc != nil {
+			recurrenceIDProp = fmt.Sprintf("RECURRENCE-ID;TZID=%s", e.Timezone)
+			recurrenceID = e.RecurrenceID.In(loc).Format("20060102T150405")
+		} else {
+			recurrenceID = e.RecurrenceID.UTC().Format("20060102T150405Z")
+		}
+		ics.WriteString(fmt.Sprintf("%s:%s\r\n", recurrenceIDProp, recurrenceID))
+	}
+	if e.RecurrenceRule != "" {
+		ics.WriteString(foldLine(fmt.Sprintf("RRULE:%s", e.RecurrenceRule)))
+		for _, exdate := range e.ExceptionDates {
+			if loc != nil {
+				ics.WriteString(fmt.Sprintf("%s:%s\r\n", exdateProp, exdate.In(loc).Format("20060102T150405")))
+			} else {
+				ics.WriteString(fmt.Sprintf("%s:%s\r\n", exdateProp, exdate.UTC().Format("20060102T150405Z")))
+			}
+		}
+	}
 	ics.WriteString(foldLine(fmt.Sprintf("SUMMARY:%s", title)))
 
 	if description != "" {
@@ -71,14 +151,25 @@ func (e *CalendarEvent) Generate() string {
 		ics.WriteString(foldLine(fmt.Sprintf("ORGANIZER;CN=%s:mailto:%s", organizerName, e.OrganizerEmail)))
 	}
 
-	// Attendee
-	if e.AttendeeEmail != "" {
-		attendeeName := escapeICSText(e.AttendeeName)
-		ics.WriteString(foldLine(fmt.Sprintf("ATTENDEE;CN=%s;RSVP=TRUE:mailto:%s", attendeeName, e.AttendeeEmail)))
+	// Attendee(s)
+	attendees := e.Attendees
+	if len(attendees) == 0 && e.AttendeeEmail != "" {
+		attendees = []Attendee{{Name: e.AttendeeName, Email: e.AttendeeEmail}}
+	}
+	for _, attendee := range attendees {
+		if attendee.Email == "" {
+			continue
+		}
+		attendeeName := escapeICSText(attendee.Name)
+		ics.WriteString(foldLine(fmt.Sprintf("ATTENDEE;CN=%s;RSVP=TRUE;PARTSTAT=NEEDS-ACTION;ROLE=REQ-PARTICIPANT:mailto:%s", attendeeName, attendee.Email)))
 	}
 
-	ics.WriteString("STATUS:CONFIRMED\r\n")
-	ics.WriteString("SEQUENCE:0\r\n")
+	status := "CONFIRMED"
+	if method == "CANCEL" {
+		status = "CANCELLED"
+	}
+	ics.WriteString(fmt.Sprintf("STATUS:%s\r\n", status))
+	ics.WriteString(fmt.Sprintf("SEQUENCE:%d\r\n", e.Sequence))
 
 	// Reminder (VALARM)
 	if e.ReminderMinutes > 0 {
@@ -95,6 +186,151 @@ func (e *CalendarEvent) Generate() string {
 	return ics.String()
 }
 
+// GenerateCancellation returns the ICS content that withdraws a
+// previously-sent invite: the same UID (so clients correlate it to the
+// meeting already on the calendar), Method forced to CANCEL, Sequence
+// bumped by one, and STATUS:CANCELLED in place of CONFIRMED.
+func GenerateCancellation(orig *CalendarEvent) string {
+	cancellation := *orig
+	cancellation.Method = "CANCEL"
+	cancellation.Sequence = orig.Sequence + 1
+	return cancellation.Generate()
+}
+
+// loadTimezone resolves e.Timezone to a *time.Location, returning nil if no
+// timezone was requested or the zone name isn't recognized (in which case
+// Generate falls back to the existing floating-UTC behavior rather than
+// failing the whole invite).
+func (e *CalendarEvent) loadTimezone() *time.Location {
+	if e.Timezone == "" {
+		return nil
+	}
+	loc, err := time.LoadLocation(e.Timezone)
+	if err != nil {
+		return nil
+	}
+	return loc
+}
+
+// vtimezoneBlock emits a VTIMEZONE component for loc. When loc observes a
+// DST transition within at's calendar year (UTC), both a STANDARD and a
+// DAYLIGHT sub-component are written, each with a YEARLY RRULE derived from
+// the detected transition date, so the recurrence is expressed the way
+// clients expect rather than repeating a fixed list of dates. Zones that
+// don't observe DST (or where no transition falls in that year) fall back
+// to the single fixed-offset STANDARD block this always used to emit.
+func vtimezoneBlock(tzid string, loc *time.Location, at time.Time) string {
+	yearStart := time.Date(at.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	yearEnd := yearStart.AddDate(1, 0, 0)
+
+	var vtz strings.Builder
+	vtz.WriteString("BEGIN:VTIMEZONE\r\n")
+	vtz.WriteString(foldLine(fmt.Sprintf("TZID:%s", tzid)))
+
+	first, foundFirst := findZoneTransition(loc, yearStart, yearEnd)
+	if !foundFirst {
+		offset := zoneOffsetAt(loc, at)
+		writeZoneComponent(&vtz, "STANDARD", offset, offset, time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC), "")
+		vtz.WriteString("END:VTIMEZONE\r\n")
+		return vtz.String()
+	}
+
+	offsetBeforeFirst := zoneOffsetAt(loc, first.Add(-time.Hour))
+	offsetAfterFirst := zoneOffsetAt(loc, first)
+	firstName := "DAYLIGHT"
+	secondName := "STANDARD"
+	if offsetAfterFirst < offsetBeforeFirst {
+		firstName, secondName = "STANDARD", "DAYLIGHT"
+	}
+	writeZoneComponent(&vtz, firstName, offsetBeforeFirst, offsetAfterFirst, first.In(loc), yearlyByDayRule(first.In(loc)))
+
+	if second, foundSecond := findZoneTransition(loc, first.Add(24*time.Hour), yearEnd); foundSecond {
+		offsetAfterSecond := zoneOffsetAt(loc, second)
+		writeZoneComponent(&vtz, secondName, offsetAfterFirst, offsetAfterSecond, second.In(loc), yearlyByDayRule(second.In(loc)))
+	}
+
+	vtz.WriteString("END:VTIMEZONE\r\n")
+	return vtz.String()
+}
+
+// writeZoneComponent writes one STANDARD or DAYLIGHT sub-component. rrule,
+// when non-empty, is written as a YEARLY recurrence; an empty rrule (the
+// no-DST case) leaves the sub-component as a one-time fixed offset.
+func writeZoneComponent(vtz *strings.Builder, name string, offsetFrom, offsetTo int, dtstart time.Time, rrule string) {
+	vtz.WriteString(fmt.Sprintf("BEGIN:%s\r\n", name))
+	vtz.WriteString(fmt.Sprintf("DTSTART:%s\r\n", dtstart.Format("20060102T150405")))
+	vtz.WriteString(fmt.Sprintf("TZOFFSETFROM:%s\r\n", formatZoneOffset(offsetFrom)))
+	vtz.WriteString(fmt.Sprintf("TZOFFSETTO:%s\r\n", formatZoneOffset(offsetTo)))
+	if rrule != "" {
+		vtz.WriteString(foldLine(fmt.Sprintf("RRULE:%s", rrule)))
+	}
+	vtz.WriteString(fmt.Sprintf("END:%s\r\n", name))
+}
+
+// zoneOffsetAt returns the offset in effect at t, in seconds east of UTC.
+func zoneOffsetAt(loc *time.Location, t time.Time) int {
+	_, offset := t.In(loc).Zone()
+	return offset
+}
+
+// findZoneTransition narrows the first point after from (and before to) at
+// which loc's UTC offset changes, first scanning a day at a time and then
+// an hour at a time within the day the change falls on. It returns the
+// first instant the new offset is in effect.
+func findZoneTransition(loc *time.Location, from, to time.Time) (time.Time, bool) {
+	fromOffset := zoneOffsetAt(loc, from)
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		next := day.AddDate(0, 0, 1)
+		if zoneOffsetAt(loc, next) == fromOffset {
+			continue
+		}
+		for h := 0; h < 24; h++ {
+			candidate := day.Add(time.Duration(h) * time.Hour)
+			if zoneOffsetAt(loc, candidate) != fromOffset {
+				return candidate, true
+			}
+		}
+		return next, true
+	}
+	return time.Time{}, false
+}
+
+// yearlyByDayRule describes t's weekday-of-month as a
+// "FREQ=YEARLY;BYMONTH=..;BYDAY=.." rule (e.g. the second Sunday in March),
+// using a trailing "-1" ordinal when t falls in the last occurrence of that
+// weekday in its month, so the rule keeps matching in years where the month
+// has a different number of those weekdays.
+func yearlyByDayRule(t time.Time) string {
+	daysInMonth := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	ordinal := (t.Day()-1)/7 + 1
+	if t.Day()+7 > daysInMonth {
+		ordinal = -1
+	}
+	return fmt.Sprintf("FREQ=YEARLY;BYMONTH=%d;BYDAY=%d%s", int(t.Month()), ordinal, weekdayAbbrev[t.Weekday()])
+}
+
+// weekdayAbbrev maps a time.Weekday to its iCalendar BYDAY abbreviation.
+var weekdayAbbrev = map[time.Weekday]string{
+	time.Sunday:    "SU",
+	time.Monday:    "MO",
+	time.Tuesday:   "TU",
+	time.Wednesday: "WE",
+	time.Thursday:  "TH",
+	time.Friday:    "FR",
+	time.Saturday:  "SA",
+}
+
+// formatZoneOffset renders a signed UTC offset in seconds as iCalendar's
+// "+HHMM"/"-HHMM" form.
+func formatZoneOffset(offsetSeconds int) string {
+	sign := "+"
+	if offsetSeconds < 0 {
+		sign = "-"
+		offsetSeconds = -offsetSeconds
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, offsetSeconds/3600, (offsetSeconds%3600)/60)
+}
+
 // escapeICSText escapes special characters for .ICS format
 func escapeICSText(text string) string {
 	// First escape backslashes, then replace newlines
@@ -0,0 +1,114 @@
+package ics
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateFoldsLongRRULELine(t *testing.T) {
+	e := &CalendarEvent{
+		UID:            "gophish-1@gophish.local",
+		Title:          "Quarterly Security Awareness Sync",
+		StartTime:      time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC),
+		EndTime:        time.Date(2026, 3, 2, 16, 0, 0, 0, time.UTC),
+		OrganizerEmail: "organizer@example.com",
+		AttendeeEmail:  "victim@example.com",
+		RecurrenceRule: "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR;COUNT=52;INTERVAL=1;WKST=MO;BYMONTH=1,2,3,4,5,6,7,8,9,10,11,12",
+	}
+
+	out := e.Generate()
+	assertFolded(t, out, "RRULE:")
+}
+
+func TestGenerateFoldsTZIDParameter(t *testing.T) {
+	e := &CalendarEvent{
+		UID:            "gophish-2@gophish.local",
+		Title:          "Sync",
+		StartTime:      time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC),
+		EndTime:        time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC),
+		OrganizerEmail: "organizer@example.com",
+		AttendeeEmail:  "victim@example.com",
+		Timezone:       "America/Argentina/Buenos_Aires",
+	}
+
+	out := e.Generate()
+	assertFolded(t, out, "TZID:")
+	assertFolded(t, out, "DTSTART;TZID=")
+}
+
+// assertFolded checks that some line contains substr, and that every line
+// containing substr and every continuation line (one starting with a space)
+// respects RFC 5545's 75-octet limit.
+func assertFolded(t *testing.T, ics, substr string) {
+	t.Helper()
+	found := false
+	for _, line := range strings.Split(strings.ReplaceAll(ics, "\r\n", "\n"), "\n") {
+		if !strings.Contains(line, substr) && !strings.HasPrefix(line, " ") {
+			continue
+		}
+		if strings.Contains(line, substr) {
+			found = true
+		}
+		if len(line) > 75 {
+			t.Errorf("line exceeds 75 octets: %q (%d)", line, len(line))
+		}
+	}
+	if !found {
+		t.Fatalf("no line containing %q found in output:\n%s", substr, ics)
+	}
+}
+
+func TestVTimezoneDetectsDSTTransition(t *testing.T) {
+	if _, err := time.LoadLocation("America/New_York"); err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+
+	e := &CalendarEvent{
+		UID:            "gophish-3@gophish.local",
+		Title:          "Sync",
+		StartTime:      time.Date(2026, 7, 1, 13, 0, 0, 0, time.UTC),
+		EndTime:        time.Date(2026, 7, 1, 14, 0, 0, 0, time.UTC),
+		OrganizerEmail: "organizer@example.com",
+		AttendeeEmail:  "victim@example.com",
+		Timezone:       "America/New_York",
+	}
+
+	out := e.Generate()
+	if !strings.Contains(out, "BEGIN:DAYLIGHT") {
+		t.Errorf("expected a DAYLIGHT sub-component for a DST-observing zone, got:\n%s", out)
+	}
+	if !strings.Contains(out, "BEGIN:STANDARD") {
+		t.Errorf("expected a STANDARD sub-component for a DST-observing zone, got:\n%s", out)
+	}
+}
+
+func TestGenerateCancellationBumpsSequenceAndStatus(t *testing.T) {
+	orig := &CalendarEvent{
+		UID:            "gophish-4@gophish.local",
+		Title:          "Sync",
+		StartTime:      time.Date(2026, 4, 1, 10, 0, 0, 0, time.UTC),
+		EndTime:        time.Date(2026, 4, 1, 11, 0, 0, 0, time.UTC),
+		OrganizerEmail: "organizer@example.com",
+		AttendeeEmail:  "victim@example.com",
+		Sequence:       2,
+	}
+
+	out := GenerateCancellation(orig)
+	if !strings.Contains(out, "METHOD:CANCEL") {
+		t.Errorf("expected METHOD:CANCEL, got:\n%s", out)
+	}
+	if !strings.Contains(out, "STATUS:CANCELLED") {
+		t.Errorf("expected STATUS:CANCELLED, got:\n%s", out)
+	}
+	if !strings.Contains(out, "SEQUENCE:3") {
+		t.Errorf("expected SEQUENCE to bump from 2 to 3, got:\n%s", out)
+	}
+	if !strings.Contains(out, "UID:"+orig.UID) {
+		t.Errorf("expected cancellation to keep the original UID, got:\n%s", out)
+	}
+	// Original event must be unaffected by generating its cancellation.
+	if orig.Sequence != 2 || orig.Method != "" {
+		t.Errorf("GenerateCancellation must not mutate its argument, got Sequence=%d Method=%q", orig.Sequence, orig.Method)
+	}
+}
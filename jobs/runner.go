@@ -0,0 +1,85 @@
+// Package jobs runs long admin operations (campaign purge, restore, bulk
+// trash actions, ...) in the background and tracks them as models.Job
+// records, so the API can respond immediately and let the caller poll
+// GET /api/jobs/{id} for completion instead of blocking the request.
+package jobs
+
+import (
+	log "github.com/gophish/gophish/logger"
+	"github.com/gophish/gophish/models"
+)
+
+// Handler does the actual work for a job. Its returned map becomes the
+// job's result payload on success; a returned error is recorded as the
+// job's (single) error and marks it failed.
+type Handler func() (map[string]interface{}, error)
+
+// numWorkers bounds how many handlers run concurrently, so a burst of
+// enqueued work (e.g. a bulk restore/purge/delete) can't spawn unbounded
+// concurrent DB transactions. queueSize bounds how much work can back up
+// behind the pool before Enqueue starts blocking its caller.
+const (
+	numWorkers = 4
+	queueSize  = 256
+)
+
+// task pairs a Job's id with the handler that produces its result, so a
+// worker goroutine pulled off the queue knows which Job record to update.
+type task struct {
+	jobID   string
+	handler Handler
+}
+
+// Runner executes registered handlers across a bounded pool of worker
+// goroutines, consuming from a buffered queue, and updates the backing
+// models.Job record as each task completes.
+type Runner struct {
+	queue chan task
+}
+
+// NewRunner creates a job Runner and starts its worker pool.
+func NewRunner() *Runner {
+	r := &Runner{
+		queue: make(chan task, queueSize),
+	}
+	for i := 0; i < numWorkers; i++ {
+		go r.worker()
+	}
+	return r
+}
+
+// worker consumes tasks off the queue until the Runner is torn down.
+func (r *Runner) worker() {
+	for t := range r.queue {
+		r.run(t.jobID, t.handler)
+	}
+}
+
+// Enqueue creates a processing Job of the given type for entityID, queues
+// handler for the worker pool to pick up, and returns the Job immediately
+// so the caller can respond 202 Accepted with a Location pointing at it.
+func (r *Runner) Enqueue(jobType string, entityID int64, handler Handler) (*models.Job, error) {
+	job := models.NewJob(jobType, entityID)
+	if err := models.CreateJob(job); err != nil {
+		return nil, err
+	}
+
+	r.queue <- task{jobID: job.Id, handler: handler}
+
+	return job, nil
+}
+
+func (r *Runner) run(jobID string, handler Handler) {
+	result, err := handler()
+	if err != nil {
+		log.Errorf("Job %s failed: %v", jobID, err)
+		if cerr := models.CompleteJob(jobID, models.JobStateFailed, nil, []string{err.Error()}); cerr != nil {
+			log.Errorf("Failed to record failure for job %s: %v", jobID, cerr)
+		}
+		return
+	}
+
+	if err := models.CompleteJob(jobID, models.JobStateComplete, result, nil); err != nil {
+		log.Errorf("Failed to record completion for job %s: %v", jobID, err)
+	}
+}
@@ -12,6 +12,8 @@ const (
 	AuditCampaignSoftDeleted = "CAMPAIGN_SOFT_DELETED"
 	AuditCampaignRestored    = "CAMPAIGN_RESTORED"
 	AuditCampaignPurged      = "CAMPAIGN_PURGED"
+	AuditLegalHoldPlaced     = "CAMPAIGN_LEGAL_HOLD_PLACED"
+	AuditLegalHoldReleased   = "CAMPAIGN_LEGAL_HOLD_RELEASED"
 )
 
 // AuditLog represents an audit trail entry
@@ -0,0 +1,143 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	log "github.com/gophish/gophish/logger"
+)
+
+// AuditPurgeExecution is a first-class record of a single AuditPurgeJob run,
+// mirroring PurgeExecution for the campaign-trash TTL job, so an operator
+// can prove to auditors exactly which retention policy removed which rows
+// and when.
+type AuditPurgeExecution struct {
+	Id              int64      `json:"id" gorm:"primaryKey"`
+	Trigger         string     `json:"trigger"` // scheduled, manual
+	StartTime       time.Time  `json:"start_time"`
+	EndTime         *time.Time `json:"end_time,omitempty"`
+	Status          string     `json:"status"` // running, success, error, stopped
+	CutoffTime      time.Time  `json:"cutoff_time"`
+	DryRun          bool       `json:"dry_run"`
+	IncludeEventLog bool       `json:"include_event_log"`
+	AuditRowsPurged int        `json:"audit_rows_purged"`
+	EventRowsPurged int        `json:"event_rows_purged"`
+}
+
+// TableName specifies the table name for AuditPurgeExecution
+func (AuditPurgeExecution) TableName() string {
+	return "audit_purge_executions"
+}
+
+// CreateAuditPurgeExecution inserts a running AuditPurgeExecution row at the
+// start of a run and returns it so the caller can fill in the outcome once
+// the purge completes.
+func CreateAuditPurgeExecution(trigger string, cutoff time.Time, dryRun, includeEventLog bool) (*AuditPurgeExecution, error) {
+	if db == nil {
+		return nil, errors.New("database not initialized")
+	}
+	exec := &AuditPurgeExecution{
+		Trigger:         trigger,
+		StartTime:       time.Now().UTC(),
+		Status:          PurgeStatusRunning,
+		CutoffTime:      cutoff,
+		DryRun:          dryRun,
+		IncludeEventLog: includeEventLog,
+	}
+	if err := db.Create(exec).Error; err != nil {
+		log.Errorf("Failed to create audit purge execution: %v", err)
+		return nil, err
+	}
+	return exec, nil
+}
+
+// CompleteAuditPurgeExecution flushes the final outcome of an AuditPurgeJob
+// run.
+func CompleteAuditPurgeExecution(exec *AuditPurgeExecution, status string, auditRowsPurged, eventRowsPurged int) error {
+	now := time.Now().UTC()
+	exec.EndTime = &now
+	exec.Status = status
+	exec.AuditRowsPurged = auditRowsPurged
+	exec.EventRowsPurged = eventRowsPurged
+
+	if err := db.Save(exec).Error; err != nil {
+		log.Errorf("Failed to complete audit purge execution %d: %v", exec.Id, err)
+		return err
+	}
+	return nil
+}
+
+// GetAuditPurgeExecution returns a single execution by ID.
+func GetAuditPurgeExecution(id int64) (*AuditPurgeExecution, error) {
+	exec := &AuditPurgeExecution{}
+	if err := db.First(exec, id).Error; err != nil {
+		return nil, err
+	}
+	return exec, nil
+}
+
+// GetAuditPurgeExecutionsPaginated lists audit purge executions, optionally
+// filtered by status, newest first.
+func GetAuditPurgeExecutionsPaginated(status string, offset, limit int) ([]AuditPurgeExecution, int64, error) {
+	execs := []AuditPurgeExecution{}
+	var total int64
+
+	query := db.Model(&AuditPurgeExecution{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("start_time DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&execs).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return execs, total, nil
+}
+
+// PurgeAuditLogs deletes audit_log rows (and, if includeEventLog is set,
+// campaign Event rows) older than cutoff. When dryRun is true, it only
+// counts what would be deleted, so an operator can preview a retention
+// change before it takes effect.
+func PurgeAuditLogs(cutoff time.Time, includeEventLog, dryRun bool) (auditRows int, eventRows int, err error) {
+	if db == nil {
+		return 0, 0, errors.New("database not initialized")
+	}
+
+	var auditCount int64
+	if err := db.Model(&AuditLog{}).Where("timestamp < ?", cutoff).Count(&auditCount).Error; err != nil {
+		return 0, 0, err
+	}
+
+	var eventCount int64
+	if includeEventLog {
+		if err := db.Model(&Event{}).Where("time < ?", cutoff).Count(&eventCount).Error; err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if dryRun {
+		return int(auditCount), int(eventCount), nil
+	}
+
+	if err := db.Where("timestamp < ?", cutoff).Delete(&AuditLog{}).Error; err != nil {
+		log.Errorf("Failed to purge audit logs older than %s: %v", cutoff.Format(time.RFC3339), err)
+		return 0, 0, err
+	}
+
+	if includeEventLog {
+		if err := db.Where("time < ?", cutoff).Delete(&Event{}).Error; err != nil {
+			log.Errorf("Failed to purge event log rows older than %s: %v", cutoff.Format(time.RFC3339), err)
+			return int(auditCount), 0, err
+		}
+	}
+
+	return int(auditCount), int(eventCount), nil
+}
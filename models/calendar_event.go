@@ -10,11 +10,12 @@ import (
 type CalendarEvent struct {
 	Id        int64     `json:"id"`
 	ResultId  int64     `json:"result_id"`
-	EventType string    `json:"event_type"` // ics_sent, link_opened, credentials_submitted, reported
+	EventType string    `json:"event_type"` // ics_sent, link_opened, credentials_submitted, reported, rsvp_accepted, rsvp_tentative, rsvp_declined, rsvp_unknown, digest_item_included
 	Timestamp time.Time `json:"timestamp"`
 	IP        string    `json:"ip,omitempty"`
 	UserAgent string    `json:"user_agent,omitempty"`
 	Details   string    `json:"details,omitempty"` // JSON field for additional metadata
+	Sequence  int       `json:"sequence,omitempty"` // iCalendar SEQUENCE this event was sent/received at, for recurring or re-sent invites
 }
 
 // SaveCalendarEvent saves a calendar event to the database
@@ -39,6 +40,20 @@ func GetCalendarEventsByResult(resultId int64) ([]CalendarEvent, error) {
 	return events, err
 }
 
+// CountCalendarEventsByResultAndType returns how many times an event of the
+// given type has already been recorded for a result, e.g. to derive the next
+// SEQUENCE number for a re-sent or recurring invite.
+func CountCalendarEventsByResultAndType(resultId int64, eventType string) (int, error) {
+	var count int
+	err := db.Model(&CalendarEvent{}).
+		Where("result_id = ? AND event_type = ?", resultId, eventType).
+		Count(&count).Error
+	if err != nil {
+		log.Error(err)
+	}
+	return count, err
+}
+
 // GetCalendarEventsByCampaign returns all calendar events for a given campaign
 func GetCalendarEventsByCampaign(campaignId int64) ([]CalendarEvent, error) {
 	events := []CalendarEvent{}
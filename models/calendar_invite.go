@@ -0,0 +1,70 @@
+package models
+
+import (
+	"encoding/json"
+
+	"github.com/gophish/gophish/ics"
+	log "github.com/gophish/gophish/logger"
+)
+
+// CalendarInvitePayload lets a calendar campaign author override the
+// meeting-invite fields that ICS generation would otherwise derive solely
+// from the Organizer/recipient pair: additional ATTENDEE lines (e.g. a
+// shared mailbox CC'd on the lure) and the IANA timezone the invite should
+// be expressed in. It is stored as JSON on the campaign so adding a field
+// here doesn't require a migration.
+type CalendarInvitePayload struct {
+	Organizer CalendarInviteAttendee   `json:"organizer"`
+	Attendees []CalendarInviteAttendee `json:"attendees,omitempty"`
+	Timezone  string                   `json:"timezone,omitempty"`
+}
+
+// CalendarInviteAttendee is a single mailbox/CN pair written to an ORGANIZER
+// or ATTENDEE property.
+type CalendarInviteAttendee struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// ParseCalendarInvitePayload decodes a campaign's stored invite payload
+// JSON. An empty string isn't an error - it just means the campaign hasn't
+// customized the invite beyond the OrganizerName/OrganizerEmail fields.
+func ParseCalendarInvitePayload(raw string) (*CalendarInvitePayload, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	payload := &CalendarInvitePayload{}
+	if err := json.Unmarshal([]byte(raw), payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// resolveCalendarInvite merges a campaign's optional CalendarInvitePayload
+// (an ORGANIZER override, extra ATTENDEEs, a pinned timezone) with its
+// default Organizer fields and the primary recipient, ready to hand to
+// ics.CalendarEvent.
+func resolveCalendarInvite(c *Campaign, r *Result) (organizerName, organizerEmail, timezone string, attendees []ics.Attendee) {
+	organizerName, organizerEmail = c.OrganizerName, c.OrganizerEmail
+	attendees = []ics.Attendee{{Name: r.FormatName(), Email: r.Email}}
+
+	payload, err := ParseCalendarInvitePayload(c.CalendarInvitePayload)
+	if err != nil {
+		log.Warnf("Invalid calendar invite payload for campaign %d: %v", c.Id, err)
+		return organizerName, organizerEmail, timezone, attendees
+	}
+	if payload == nil {
+		return organizerName, organizerEmail, timezone, attendees
+	}
+
+	if payload.Organizer.Email != "" {
+		organizerName, organizerEmail = payload.Organizer.Name, payload.Organizer.Email
+	}
+	timezone = payload.Timezone
+	for _, a := range payload.Attendees {
+		if a.Email != "" {
+			attendees = append(attendees, ics.Attendee{Name: a.Name, Email: a.Email})
+		}
+	}
+	return organizerName, organizerEmail, timezone, attendees
+}
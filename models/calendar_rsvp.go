@@ -0,0 +1,287 @@
+package models
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"regexp"
+	"strings"
+
+	log "github.com/gophish/gophish/logger"
+)
+
+// uidPattern extracts the RID that GenerateMailLog/GenerateICSForResult
+// embedded in the UID, e.g. "gophish-<rid>@gophish.local".
+var uidPattern = regexp.MustCompile(`(?i)^gophish-(.+)@gophish\.local$`)
+
+// partstatEventTypes maps the iCalendar ATTENDEE PARTSTAT value to the
+// CalendarEvent EventType recorded for an inbound RSVP.
+var partstatEventTypes = map[string]string{
+	"ACCEPTED":  "rsvp_accepted",
+	"TENTATIVE": "rsvp_tentative",
+	"DECLINED":  "rsvp_declined",
+}
+
+// ErrRSVPNoMatch is returned when an inbound REPLY's UID doesn't correlate
+// to a known Result.
+var ErrRSVPNoMatch = fmt.Errorf("no result matched the REPLY UID")
+
+// CalendarRSVP holds the fields parsed out of an inbound METHOD:REPLY
+// message, before it is correlated to a Result.
+type CalendarRSVP struct {
+	UID       string
+	PartStat  string
+	Responder string
+	RawICS    string
+}
+
+// ParseCalendarReply parses a raw inbound email (as delivered by an MX relay
+// webhook or fetched via IMAP) looking for its text/calendar part; the part's
+// METHOD value is not checked, so any calendar part is accepted. It
+// tolerates quoted-printable encoding, multipart/alternative wrappers, and
+// case-insensitive parameter names, mirroring how mail clients locate the
+// invite part to build their own accept/tentative/decline replies.
+func ParseCalendarReply(raw []byte) (*CalendarRSVP, error) {
+	msg, err := readMIMEMessage(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	ics, err := findCalendarPart(msg.header, msg.body)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseReplyICS(ics)
+}
+
+// mimePart is a minimal representation of a parsed email/MIME part.
+type mimePart struct {
+	header map[string]string
+	body   []byte
+}
+
+func readMIMEMessage(raw []byte) (*mimePart, error) {
+	reader := bufio.NewReader(strings.NewReader(string(raw)))
+	header := map[string]string{}
+
+	var lastKey string
+	for {
+		line, err := reader.ReadString('\n')
+		trimmed := strings.TrimRight(line, "\r\n")
+		if trimmed == "" {
+			break
+		}
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && lastKey != "" {
+			header[lastKey] += " " + strings.TrimSpace(trimmed)
+		} else if idx := strings.IndexByte(trimmed, ':'); idx > 0 {
+			key := strings.ToLower(strings.TrimSpace(trimmed[:idx]))
+			header[key] = strings.TrimSpace(trimmed[idx+1:])
+			lastKey = key
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	return &mimePart{header: header, body: body}, nil
+}
+
+// findCalendarPart walks a (possibly multipart/alternative) message looking
+// for a text/calendar part, decoding quoted-printable bodies as needed.
+func findCalendarPart(header map[string]string, body []byte) (string, error) {
+	contentType := header["content-type"]
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		// No usable Content-Type header; assume the body is the ICS itself.
+		return decodeBody(header, body), nil
+	}
+
+	if strings.HasPrefix(strings.ToLower(mediaType), "multipart/") {
+		boundary := params["boundary"]
+		if boundary == "" {
+			return "", fmt.Errorf("multipart message missing boundary")
+		}
+		mr := multipart.NewReader(strings.NewReader(string(body)), boundary)
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return "", err
+			}
+			partHeader := map[string]string{}
+			for k, v := range part.Header {
+				if len(v) > 0 {
+					partHeader[strings.ToLower(k)] = v[0]
+				}
+			}
+			partBody, err := io.ReadAll(part)
+			if err != nil {
+				return "", err
+			}
+			partType := strings.ToLower(partHeader["content-type"])
+			if strings.Contains(partType, "text/calendar") {
+				return decodeBody(partHeader, partBody), nil
+			}
+			if strings.Contains(partType, "multipart/") {
+				if nested, err := findCalendarPart(partHeader, partBody); err == nil && nested != "" {
+					return nested, nil
+				}
+			}
+		}
+		return "", fmt.Errorf("no text/calendar part found")
+	}
+
+	if strings.Contains(strings.ToLower(mediaType), "text/calendar") {
+		return decodeBody(header, body), nil
+	}
+
+	return decodeBody(header, body), nil
+}
+
+func decodeBody(header map[string]string, body []byte) string {
+	encoding := strings.ToLower(strings.TrimSpace(header["content-transfer-encoding"]))
+	switch encoding {
+	case "quoted-printable":
+		decoded, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(string(body))))
+		if err != nil {
+			log.Warnf("Failed to decode quoted-printable REPLY body: %v", err)
+			return string(body)
+		}
+		return string(decoded)
+	default:
+		return string(body)
+	}
+}
+
+// parseReplyICS extracts UID, ATTENDEE PARTSTAT, and the responder mailbox
+// from a raw ICS blob, tolerating case-insensitive parameter names.
+func parseReplyICS(ics string) (*CalendarRSVP, error) {
+	rsvp := &CalendarRSVP{RawICS: ics}
+
+	lines := unfoldICSLines(ics)
+	for _, line := range lines {
+		upper := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(upper, "UID"):
+			if idx := strings.IndexByte(line, ':'); idx >= 0 {
+				rsvp.UID = strings.TrimSpace(line[idx+1:])
+			}
+		case strings.HasPrefix(upper, "ATTENDEE"):
+			idx := strings.IndexByte(line, ':')
+			if idx < 0 {
+				continue
+			}
+			paramsPart := line[:idx]
+			mailbox := strings.TrimSpace(line[idx+1:])
+			rsvp.Responder = strings.TrimPrefix(strings.ToLower(mailbox), "mailto:")
+			rsvp.PartStat = strings.ToUpper(extractICSParam(paramsPart, "PARTSTAT"))
+		}
+	}
+
+	if rsvp.UID == "" {
+		return nil, fmt.Errorf("REPLY is missing a UID")
+	}
+	return rsvp, nil
+}
+
+// unfoldICSLines reverses RFC 5545 line folding (a leading space/tab
+// continues the previous line) before scanning for properties.
+func unfoldICSLines(ics string) []string {
+	raw := strings.Split(strings.ReplaceAll(ics, "\r\n", "\n"), "\n")
+	var lines []string
+	for _, l := range raw {
+		if (strings.HasPrefix(l, " ") || strings.HasPrefix(l, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += strings.TrimPrefix(strings.TrimPrefix(l, " "), "\t")
+			continue
+		}
+		lines = append(lines, l)
+	}
+	return lines
+}
+
+// extractICSParam does a case-insensitive lookup of a `;NAME=value` style
+// parameter within an ICS property's parameter list.
+func extractICSParam(paramsPart, name string) string {
+	for _, p := range strings.Split(paramsPart, ";") {
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		if strings.EqualFold(strings.TrimSpace(kv[0]), name) {
+			return strings.TrimSpace(kv[1])
+		}
+	}
+	return ""
+}
+
+// HandleCalendarReply correlates an inbound REPLY to its Result via the UID,
+// records a CalendarEvent capturing the RSVP, and updates the Result
+// timeline. It is the entry point for both the webhook and IMAP-polling
+// ingestion paths.
+func HandleCalendarReply(rsvp *CalendarRSVP) (*CalendarEvent, error) {
+	matches := uidPattern.FindStringSubmatch(rsvp.UID)
+	if matches == nil {
+		log.Warnf("Calendar REPLY UID %q did not match the gophish UID format", rsvp.UID)
+		return nil, ErrRSVPNoMatch
+	}
+	rid := matches[1]
+
+	r, err := GetResult(rid)
+	if err != nil {
+		log.Warnf("Calendar REPLY for unknown RID=%s: %v", rid, err)
+		return nil, ErrRSVPNoMatch
+	}
+
+	eventType, ok := partstatEventTypes[rsvp.PartStat]
+	if !ok {
+		eventType = "rsvp_unknown"
+	}
+
+	details := map[string]string{
+		"responder": rsvp.Responder,
+		"partstat":  rsvp.PartStat,
+		"ics":       rsvp.RawICS,
+	}
+	detailsBytes, err := json.Marshal(details)
+	if err != nil {
+		return nil, err
+	}
+	detailsJSON := string(detailsBytes)
+
+	calEvent := &CalendarEvent{
+		ResultId:  r.Id,
+		EventType: eventType,
+		Details:   detailsJSON,
+	}
+	if err := SaveCalendarEvent(calEvent); err != nil {
+		return nil, err
+	}
+
+	// CalendarEvent above is the detailed, calendar-specific audit row; this
+	// Event is what actually surfaces the RSVP on the campaign timeline UI,
+	// the same way handleCalendarPhishPOST's AddEvent call does for a form
+	// submission.
+	err = AddEvent(&Event{
+		Email:      r.Email,
+		Message:    fmt.Sprintf("Calendar RSVP: %s", eventType),
+		CampaignId: r.CampaignId,
+		Details:    detailsJSON,
+	}, r.CampaignId)
+	if err != nil {
+		log.Errorf("Failed to record timeline event for calendar RSVP RID=%s: %v", rid, err)
+	}
+
+	log.Infof("Calendar RSVP recorded: RID=%s, PARTSTAT=%s, from=%s", rid, rsvp.PartStat, rsvp.Responder)
+	return calEvent, nil
+}
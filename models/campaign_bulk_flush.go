@@ -0,0 +1,447 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/gophish/gophish/logger"
+	"github.com/jinzhu/gorm"
+)
+
+// bulkFlushChunkSize is the default number of campaigns processed per
+// transaction by the Bulk* flush functions below. Unlike
+// bulkTrashListPageSize (which pages "all" candidates out of the database),
+// this bounds how many rows a single locked transaction touches, trading a
+// bit of lock hold time for far fewer round-trips than one transaction per
+// campaign.
+var bulkFlushChunkSize = 50
+
+// BulkResult is the outcome of a chunked bulk flush (SoftDeleteCampaignsBulk,
+// RestoreCampaignsBulk, PurgeCampaignsBulk). Skipped covers expected no-ops
+// (already in the target state, under legal hold, not found) that shouldn't
+// be reported as errors. Those functions never fill Failed: when a chunk's
+// transaction fails they stop and return that error, so the ids of that
+// chunk and of every later chunk are in none of the three.
+type BulkResult struct {
+	Succeeded       []int64
+	Skipped         map[int64]string
+	Failed          map[int64]error
+	Warnings        map[int64][]string
+	ChunksCommitted int
+}
+
+func newBulkResult() BulkResult {
+	return BulkResult{
+		Succeeded: []int64{},
+		Skipped:   map[int64]string{},
+		Failed:    map[int64]error{},
+		Warnings:  map[int64][]string{},
+	}
+}
+
+// chunkIDs splits ids into slices of at most size, preserving order.
+func chunkIDs(ids []int64, size int) [][]int64 {
+	if size <= 0 {
+		size = bulkFlushChunkSize
+	}
+	chunks := make([][]int64, 0, (len(ids)+size-1)/size)
+	for len(ids) > 0 {
+		n := size
+		if n > len(ids) {
+			n = len(ids)
+		}
+		chunks = append(chunks, ids[:n])
+		ids = ids[n:]
+	}
+	return chunks
+}
+
+// bulkInsertAuditLogs writes every entry in one INSERT statement instead of
+// one Create call per row, so a chunk's audit trail costs a single
+// round-trip regardless of chunk size.
+func bulkInsertAuditLogs(tx *gorm.DB, entries []*AuditLog) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	now := time.Now().UTC()
+	placeholders := make([]string, 0, len(entries))
+	args := make([]interface{}, 0, len(entries)*7)
+	for _, e := range entries {
+		if e.Timestamp.IsZero() {
+			e.Timestamp = now
+		}
+		placeholders = append(placeholders, "(?, ?, ?, ?, ?, ?, ?)")
+		args = append(args, e.Timestamp, e.ActorID, e.ActorName, e.Action, e.EntityType, e.EntityID, e.Metadata)
+	}
+	query := fmt.Sprintf(
+		"INSERT INTO audit_log (timestamp, actor_id, actor_name, action, entity_type, entity_id, metadata) VALUES %s",
+		strings.Join(placeholders, ", "),
+	)
+	return tx.Exec(query, args...).Error
+}
+
+// SoftDeleteCampaignsBulk moves every campaign in ids owned by userID to
+// trash, in transactions of bulkFlushChunkSize rows. Each chunk locks its
+// rows with a single SELECT ... FOR UPDATE, applies the state transition in
+// memory, then flushes one bulk UPDATE and one bulk audit_log INSERT rather
+// than per-campaign round-trips.
+func SoftDeleteCampaignsBulk(ids []int64, userID int64, reason string) (BulkResult, error) {
+	result := newBulkResult()
+	for _, chunk := range chunkIDs(ids, bulkFlushChunkSize) {
+		if err := flushSoftDeleteChunk(chunk, userID, reason, &result); err != nil {
+			return result, err
+		}
+		result.ChunksCommitted++
+	}
+	return result, nil
+}
+
+func flushSoftDeleteChunk(chunk []int64, userID int64, reason string, result *BulkResult) error {
+	tx := db.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	var campaigns []Campaign
+	if err := lockForUpdate(tx).Where("id IN (?) AND user_id = ?", chunk, userID).Find(&campaigns).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	found := make(map[int64]*Campaign, len(campaigns))
+	for i := range campaigns {
+		found[campaigns[i].Id] = &campaigns[i]
+	}
+
+	now := time.Now().UTC()
+	statusCases := make([]string, 0, len(chunk))
+	statusBeforeCases := make([]string, 0, len(chunk))
+	statusArgs := make([]interface{}, 0, len(chunk)*2)
+	statusBeforeArgs := make([]interface{}, 0, len(chunk)*2)
+	succeeded := make([]int64, 0, len(chunk))
+	audits := make([]*AuditLog, 0, len(chunk))
+
+	for _, id := range chunk {
+		c, ok := found[id]
+		if !ok {
+			result.Skipped[id] = "not found or not owned by user"
+			continue
+		}
+		if c.IsDeleted() {
+			result.Skipped[id] = "already deleted"
+			continue
+		}
+
+		statusBefore := c.Status
+		newStatus := c.Status
+		if c.Status == CampaignInProgress || c.Status == CampaignQueued {
+			newStatus = CampaignComplete
+		}
+
+		statusCases = append(statusCases, "WHEN ? THEN ?")
+		statusArgs = append(statusArgs, id, newStatus)
+		statusBeforeCases = append(statusBeforeCases, "WHEN ? THEN ?")
+		statusBeforeArgs = append(statusBeforeArgs, id, statusBefore)
+
+		succeeded = append(succeeded, id)
+		audits = append(audits, &AuditLog{
+			ActorID:    &userID,
+			Action:     AuditCampaignSoftDeleted,
+			EntityType: "campaign",
+			EntityID:   id,
+		})
+	}
+
+	if len(succeeded) == 0 {
+		return tx.Commit().Error
+	}
+
+	for i, id := range succeeded {
+		audits[i].SetMetadata(map[string]interface{}{
+			"name":   found[id].Name,
+			"reason": reason,
+		})
+	}
+
+	query := fmt.Sprintf(
+		`UPDATE campaigns SET
+			deleted_at = ?,
+			deleted_by = ?,
+			delete_reason = ?,
+			version = version + 1,
+			status = CASE id %s END,
+			status_before_delete = CASE id %s END
+		WHERE id IN (?)`,
+		strings.Join(statusCases, " "), strings.Join(statusBeforeCases, " "),
+	)
+	execArgs := []interface{}{now, userID, reason}
+	execArgs = append(execArgs, statusArgs...)
+	execArgs = append(execArgs, statusBeforeArgs...)
+	execArgs = append(execArgs, succeeded)
+	if err := tx.Exec(query, execArgs...).Error; err != nil {
+		tx.Rollback()
+		log.Errorf("Bulk soft delete: failed to flush chunk: %v", err)
+		return err
+	}
+
+	if err := bulkInsertAuditLogs(tx, audits); err != nil {
+		tx.Rollback()
+		log.Errorf("Bulk soft delete: failed to write audit log for chunk: %v", err)
+		return err
+	}
+
+	result.Succeeded = append(result.Succeeded, succeeded...)
+	return tx.Commit().Error
+}
+
+// RestoreCampaignsBulk restores every campaign in ids owned by userID out of
+// trash, chunked the same way as SoftDeleteCampaignsBulk. Name conflicts are
+// resolved the same way restoreCampaign does for a single campaign (renamed
+// with a "(Restored <timestamp>)" suffix), recorded as a Warning rather than
+// a Failed entry.
+func RestoreCampaignsBulk(ids []int64, userID int64) (BulkResult, error) {
+	result := newBulkResult()
+	for _, chunk := range chunkIDs(ids, bulkFlushChunkSize) {
+		if err := flushRestoreChunk(chunk, userID, &result); err != nil {
+			return result, err
+		}
+		result.ChunksCommitted++
+	}
+	return result, nil
+}
+
+func flushRestoreChunk(chunk []int64, userID int64, result *BulkResult) error {
+	tx := db.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	var campaigns []Campaign
+	if err := lockForUpdate(tx).Unscoped().Where("id IN (?) AND user_id = ?", chunk, userID).Find(&campaigns).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	found := make(map[int64]*Campaign, len(campaigns))
+	for i := range campaigns {
+		found[campaigns[i].Id] = &campaigns[i]
+	}
+
+	// Load active campaign names once per chunk instead of once per row, so
+	// conflict detection doesn't reintroduce the N-round-trip problem this
+	// function exists to avoid.
+	var activeNames []string
+	if err := tx.Model(&Campaign{}).Where("user_id = ? AND deleted_at IS NULL", userID).Pluck("name", &activeNames).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	taken := make(map[string]bool, len(activeNames))
+	for _, n := range activeNames {
+		taken[strings.ToLower(n)] = true
+	}
+
+	now := time.Now().UTC()
+	nameCases := make([]string, 0, len(chunk))
+	args := make([]interface{}, 0, len(chunk)*2)
+	succeeded := make([]int64, 0, len(chunk))
+	audits := make([]*AuditLog, 0, len(chunk))
+
+	for _, id := range chunk {
+		c, ok := found[id]
+		if !ok {
+			result.Skipped[id] = "not found or not owned by user"
+			continue
+		}
+		if !c.IsDeleted() {
+			result.Skipped[id] = "not deleted"
+			continue
+		}
+
+		newName := c.Name
+		nameChanged := false
+		if taken[strings.ToLower(newName)] {
+			newName = fmt.Sprintf("%s (Restored %s)", c.Name, now.Format("2006-01-02 15:04"))
+			for i := 1; taken[strings.ToLower(newName)] && i < 10; i++ {
+				newName = fmt.Sprintf("%s (Restored %s-%d)", c.Name, now.Format("2006-01-02"), i)
+			}
+			nameChanged = true
+			result.Warnings[id] = append(result.Warnings[id],
+				fmt.Sprintf("Campaign renamed from '%s' to '%s' due to name conflict", c.Name, newName))
+		}
+		taken[strings.ToLower(newName)] = true
+
+		nameCases = append(nameCases, "WHEN ? THEN ?")
+		args = append(args, id, newName)
+
+		succeeded = append(succeeded, id)
+		audits = append(audits, &AuditLog{
+			ActorID:    &userID,
+			Action:     AuditCampaignRestored,
+			EntityType: "campaign",
+			EntityID:   id,
+		})
+		audits[len(audits)-1].SetMetadata(map[string]interface{}{
+			"name":          newName,
+			"original_name": c.Name,
+			"name_changed":  nameChanged,
+		})
+	}
+
+	if len(succeeded) == 0 {
+		return tx.Commit().Error
+	}
+
+	query := fmt.Sprintf(
+		`UPDATE campaigns SET
+			deleted_at = NULL,
+			deleted_by = NULL,
+			restored_at = ?,
+			restored_by = ?,
+			status = ?,
+			version = version + 1,
+			name = CASE id %s END
+		WHERE id IN (?)`,
+		strings.Join(nameCases, " "),
+	)
+	execArgs := append([]interface{}{now, userID, CampaignCreated}, args...)
+	execArgs = append(execArgs, succeeded)
+	if err := tx.Exec(query, execArgs...).Error; err != nil {
+		tx.Rollback()
+		log.Errorf("Bulk restore: failed to flush chunk: %v", err)
+		return err
+	}
+
+	if err := bulkInsertAuditLogs(tx, audits); err != nil {
+		tx.Rollback()
+		log.Errorf("Bulk restore: failed to write audit log for chunk: %v", err)
+		return err
+	}
+
+	result.Succeeded = append(result.Succeeded, succeeded...)
+	return tx.Commit().Error
+}
+
+// PurgeCampaignsBulk permanently deletes every campaign in ids that is in
+// trash, chunked the same way as SoftDeleteCampaignsBulk/RestoreCampaignsBulk.
+// Unlike those two, it isn't scoped to a single owning user: it's shared by
+// the admin-facing bulk-purge path and TrashTTLJob's system purge, so the
+// caller identifies itself via actorID/actorName the same way AuditLog
+// records an actor (actorID nil + actorName "system:trash-ttl" for the TTL
+// job). A campaign under legal hold is skipped unless force is true, mirroring
+// PurgeCampaign/PurgeSystemCampaign's single-campaign behavior. Cascade
+// deletes (events, results, campaign_groups, calendar_events) are collapsed
+// into one statement per table per chunk instead of one per campaign.
+func PurgeCampaignsBulk(ids []int64, actorID *int64, actorName string, force bool) (BulkResult, error) {
+	result := newBulkResult()
+	for _, chunk := range chunkIDs(ids, bulkFlushChunkSize) {
+		if err := flushPurgeChunk(chunk, actorID, actorName, force, &result); err != nil {
+			return result, err
+		}
+		result.ChunksCommitted++
+	}
+	return result, nil
+}
+
+func flushPurgeChunk(chunk []int64, actorID *int64, actorName string, force bool, result *BulkResult) error {
+	tx := db.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	var campaigns []Campaign
+	if err := lockForUpdate(tx).Where("id IN (?)", chunk).Find(&campaigns).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	found := make(map[int64]*Campaign, len(campaigns))
+	for i := range campaigns {
+		found[campaigns[i].Id] = &campaigns[i]
+	}
+
+	succeeded := make([]int64, 0, len(chunk))
+	audits := make([]*AuditLog, 0, len(chunk))
+
+	for _, id := range chunk {
+		c, ok := found[id]
+		if !ok {
+			// Already purged (or never existed) - idempotent no-op, not a failure.
+			result.Skipped[id] = "not found"
+			continue
+		}
+		if !c.IsDeleted() {
+			result.Skipped[id] = "not in trash"
+			continue
+		}
+		if c.LegalHold && !force {
+			result.Skipped[id] = "under legal hold"
+			continue
+		}
+
+		succeeded = append(succeeded, id)
+		audit := &AuditLog{
+			ActorID:    actorID,
+			ActorName:  actorName,
+			Action:     AuditCampaignPurged,
+			EntityType: "campaign",
+			EntityID:   id,
+		}
+		audit.SetMetadata(map[string]interface{}{
+			"name":                c.Name,
+			"deleted_at":          c.DeletedAt,
+			"user_id":             c.UserId,
+			"legal_hold_override": c.LegalHold && force,
+		})
+		audits = append(audits, audit)
+	}
+
+	if len(succeeded) == 0 {
+		return tx.Commit().Error
+	}
+
+	// Audit BEFORE delete (critical - must persist even after deletion).
+	if err := bulkInsertAuditLogs(tx, audits); err != nil {
+		tx.Rollback()
+		log.Errorf("CRITICAL: Bulk purge: failed to write audit log for chunk: %v", err)
+		return fmt.Errorf("failed to create audit log: %w", err)
+	}
+
+	cascades := []string{
+		"DELETE FROM calendar_events WHERE result_id IN (SELECT id FROM results WHERE campaign_id IN (?))",
+		"DELETE FROM events WHERE campaign_id IN (?)",
+		"DELETE FROM results WHERE campaign_id IN (?)",
+		"DELETE FROM campaign_groups WHERE campaign_id IN (?)",
+	}
+	for _, stmt := range cascades {
+		if err := tx.Exec(stmt, succeeded).Error; err != nil {
+			tx.Rollback()
+			log.Errorf("Bulk purge: failed to run cascade delete for chunk: %v", err)
+			return err
+		}
+	}
+
+	if err := tx.Exec("DELETE FROM campaigns WHERE id IN (?)", succeeded).Error; err != nil {
+		tx.Rollback()
+		log.Errorf("Bulk purge: failed to delete campaigns for chunk: %v", err)
+		return err
+	}
+
+	result.Succeeded = append(result.Succeeded, succeeded...)
+	return tx.Commit().Error
+}
@@ -0,0 +1,143 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// bulkTrashListPageSize bounds how many "all=true" candidates
+// ListPurgeCandidates returns per page. It's independent of
+// bulkFlushChunkSize, which bounds how many rows the *Bulk flush functions
+// below touch in a single transaction.
+const bulkTrashListPageSize = 100
+
+// ErrNoCampaignsSelected is returned when a bulk trash request resolves to
+// an empty candidate set (an empty ids list, or nothing matched "all").
+var ErrNoCampaignsSelected = errors.New("no campaigns matched the request")
+
+// BulkTrashFailure records a single campaign's failure (or skip) within a
+// bulk restore/purge, for the API's partial-success response.
+type BulkTrashFailure struct {
+	ID    int64  `json:"id"`
+	Error string `json:"error"`
+}
+
+// BulkTrashResult is the outcome of a bulk restore/purge/empty-trash job:
+// a token identifying the run, and which campaigns succeeded or failed
+// (including expected no-op skips, e.g. "not in trash").
+type BulkTrashResult struct {
+	BatchID   string             `json:"batch_id"`
+	Succeeded []int64            `json:"succeeded"`
+	Failed    []BulkTrashFailure `json:"failed"`
+}
+
+// mergeBulkResult folds a chunked flush's BulkResult into the API-facing
+// BulkTrashResult, treating Skipped the same as Failed - callers polling
+// the job only care whether an id ended up restored/purged or not.
+func mergeBulkResult(dst *BulkTrashResult, r BulkResult) {
+	dst.Succeeded = append(dst.Succeeded, r.Succeeded...)
+	for id, reason := range r.Skipped {
+		dst.Failed = append(dst.Failed, BulkTrashFailure{ID: id, Error: reason})
+	}
+	for id, err := range r.Failed {
+		dst.Failed = append(dst.Failed, BulkTrashFailure{ID: id, Error: err.Error()})
+	}
+}
+
+// runBulkTrashViaFlush resolves the candidate campaign ids - either the
+// explicit ids list, or every trashed campaign older than olderThanDays
+// when all is true - and hands them to flush in pages of
+// bulkTrashListPageSize, so an "all" run doesn't load every matching
+// campaign into memory (or one oversized transaction) at once. flush does
+// its own chunked-transaction batching internally (see
+// campaign_bulk_flush.go); context cancellation is checked between pages.
+func runBulkTrashViaFlush(ctx context.Context, ids []int64, all bool, olderThanDays int, flush func([]int64) (BulkResult, error)) (*BulkTrashResult, error) {
+	result := &BulkTrashResult{
+		BatchID:   generateJobToken(),
+		Succeeded: []int64{},
+		Failed:    []BulkTrashFailure{},
+	}
+
+	if !all {
+		if len(ids) == 0 {
+			return nil, ErrNoCampaignsSelected
+		}
+		r, err := flush(ids)
+		mergeBulkResult(result, r)
+		if err != nil {
+			return result, err
+		}
+		return result, nil
+	}
+
+	cutoff := time.Now().UTC()
+	if olderThanDays > 0 {
+		cutoff = cutoff.Add(-time.Duration(olderThanDays) * 24 * time.Hour)
+	}
+
+	matched := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		default:
+		}
+
+		page, err := ListPurgeCandidates(cutoff, bulkTrashListPageSize)
+		if err != nil {
+			return result, err
+		}
+		if len(page) == 0 {
+			break
+		}
+		matched += len(page)
+
+		before := len(result.Succeeded)
+		r, err := flush(page)
+		mergeBulkResult(result, r)
+		if err != nil {
+			return result, err
+		}
+		// A page can fail to make any progress for reasons the next
+		// ListPurgeCandidates call won't resolve on its own (e.g. a
+		// permission error), so it would keep reappearing on every
+		// subsequent page. Stop instead of looping forever.
+		if len(result.Succeeded) == before {
+			break
+		}
+	}
+
+	if matched == 0 {
+		return result, ErrNoCampaignsSelected
+	}
+	return result, nil
+}
+
+// BulkRestoreCampaigns restores either the given campaign ids or every
+// trashed campaign older than olderThanDays (when all is true), flushing
+// in chunked transactions via RestoreCampaignsBulk.
+func BulkRestoreCampaigns(ctx context.Context, ids []int64, all bool, olderThanDays int, userID int64) (*BulkTrashResult, error) {
+	return runBulkTrashViaFlush(ctx, ids, all, olderThanDays, func(batch []int64) (BulkResult, error) {
+		return RestoreCampaignsBulk(batch, userID)
+	})
+}
+
+// BulkPurgeCampaigns permanently deletes either the given campaign ids or
+// every trashed campaign older than olderThanDays (when all is true),
+// flushing in chunked transactions via PurgeCampaignsBulk. Callers must
+// already have verified the requester is an admin and checked
+// AnyCampaignInProgress. Campaigns under legal hold are skipped rather
+// than forced through, unlike the single-campaign PurgeCampaign.
+func BulkPurgeCampaigns(ctx context.Context, ids []int64, all bool, olderThanDays int, userID int64) (*BulkTrashResult, error) {
+	return runBulkTrashViaFlush(ctx, ids, all, olderThanDays, func(batch []int64) (BulkResult, error) {
+		return PurgeCampaignsBulk(batch, &userID, "", false)
+	})
+}
+
+// EmptyTrash purges every trashed campaign, regardless of age. It's the
+// "empty trash" shortcut: BulkPurgeCampaigns with all=true and no age
+// filter.
+func EmptyTrash(ctx context.Context, userID int64) (*BulkTrashResult, error) {
+	return BulkPurgeCampaigns(ctx, nil, true, 0, userID)
+}
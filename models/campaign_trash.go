@@ -15,6 +15,7 @@ var (
 	ErrCampaignNotFound = errors.New("campaign not found")
 	ErrPermissionDenied = errors.New("permission denied")
 	ErrNameConflict     = errors.New("campaign name conflict")
+	ErrLegalHold        = errors.New("campaign is under legal hold")
 )
 
 // IsDeleted returns true if campaign is in trash
@@ -140,6 +141,14 @@ type RestoreResult struct {
 
 // RestoreCampaign recupera campaña de papelera
 func RestoreCampaign(campaignID int64, userID int64) (*RestoreResult, error) {
+	return restoreCampaign(campaignID, userID, "")
+}
+
+// restoreCampaign is the implementation behind RestoreCampaign. batchID is
+// stamped onto the audit log entry's metadata; RestoreCampaign passes an
+// empty batchID. The bulk-restore job goes through RestoreCampaignsBulk
+// instead, whose audit rows carry no batch_id.
+func restoreCampaign(campaignID int64, userID int64, batchID string) (*RestoreResult, error) {
 	result := &RestoreResult{Success: false, Warnings: []string{}}
 
 	tx := db.Begin()
@@ -241,6 +250,7 @@ func RestoreCampaign(campaignID int64, userID int64) (*RestoreResult, error) {
 		"original_name": originalName,
 		"name_changed":  result.NameChanged,
 		"warnings":      result.Warnings,
+		"batch_id":      batchID,
 	})
 
 	if err := tx.Create(audit).Error; err != nil {
@@ -258,8 +268,18 @@ func RestoreCampaign(campaignID int64, userID int64) (*RestoreResult, error) {
 	return result, nil
 }
 
-// PurgeCampaign ejecuta hard delete definitivo
-func PurgeCampaign(campaignID int64, userID int64, isAdmin bool) error {
+// PurgeCampaign ejecuta hard delete definitivo. A campaign under legal hold
+// is refused unless force is true, since callers with admin privileges can
+// otherwise bypass retention entirely.
+func PurgeCampaign(campaignID int64, userID int64, isAdmin bool, force bool) error {
+	return purgeCampaign(campaignID, userID, isAdmin, force, "")
+}
+
+// purgeCampaign is the implementation behind PurgeCampaign. batchID is
+// stamped onto the audit log entry's metadata; PurgeCampaign passes an
+// empty batchID. The bulk-purge job goes through PurgeCampaignsBulk
+// instead, whose audit rows carry no batch_id.
+func purgeCampaign(campaignID int64, userID int64, isAdmin bool, force bool, batchID string) error {
 	if !isAdmin {
 		return errors.New("purge requires admin privileges")
 	}
@@ -291,6 +311,17 @@ func PurgeCampaign(campaignID int64, userID int64, isAdmin bool) error {
 		return errors.New("can only purge campaigns in trash")
 	}
 
+	// Legal hold refuses the purge unless the caller explicitly forces it.
+	if c.LegalHold && !force {
+		tx.Rollback()
+		log.Warnf("Refused to purge campaign %d: under legal hold", campaignID)
+		return ErrLegalHold
+	}
+	legalHoldOverride := c.LegalHold && force
+	if legalHoldOverride {
+		log.Warnf("Campaign %d is under legal hold; purging anyway at user %d's request (force=true)", campaignID, userID)
+	}
+
 	// Audit BEFORE delete (critical - must persist even after deletion)
 	audit := &AuditLog{
 		ActorID:    &userID,
@@ -299,9 +330,11 @@ func PurgeCampaign(campaignID int64, userID int64, isAdmin bool) error {
 		EntityID:   campaignID,
 	}
 	audit.SetMetadata(map[string]interface{}{
-		"name":       c.Name,
-		"deleted_at": c.DeletedAt,
-		"user_id":    c.UserId,
+		"name":                c.Name,
+		"deleted_at":          c.DeletedAt,
+		"user_id":             c.UserId,
+		"batch_id":            batchID,
+		"legal_hold_override": legalHoldOverride,
 	})
 
 	if err := tx.Create(audit).Error; err != nil {
@@ -350,6 +383,121 @@ func PurgeCampaign(campaignID int64, userID int64, isAdmin bool) error {
 	return tx.Commit().Error
 }
 
+// PlaceLegalHold flags campaignID so TTL auto-purge (ListPurgeCandidates,
+// PurgeSystemCampaign) skips it and a user-initiated PurgeCampaign refuses
+// it unless force is true. Restricted to admins, like PurgeCampaign itself.
+func PlaceLegalHold(campaignID int64, userID int64, reason string, isAdmin bool) error {
+	if !isAdmin {
+		return errors.New("legal hold requires admin privileges")
+	}
+
+	tx := db.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	// Unscoped since a legal hold can be placed on a campaign already in
+	// trash, awaiting its TTL purge.
+	c := &Campaign{}
+	if err := lockForUpdate(tx).Unscoped().First(c, campaignID).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCampaignNotFound
+		}
+		return err
+	}
+
+	now := time.Now().UTC()
+	c.LegalHold = true
+	c.LegalHoldReason = reason
+	c.LegalHoldBy = &userID
+	c.LegalHoldAt = &now
+
+	if err := tx.Unscoped().Save(c).Error; err != nil {
+		tx.Rollback()
+		log.Errorf("Failed to place legal hold on campaign %d: %v", campaignID, err)
+		return err
+	}
+
+	audit := &AuditLog{
+		ActorID:    &userID,
+		Action:     AuditLegalHoldPlaced,
+		EntityType: "campaign",
+		EntityID:   campaignID,
+	}
+	audit.SetMetadata(map[string]interface{}{
+		"name":   c.Name,
+		"reason": reason,
+	})
+	if err := tx.Create(audit).Error; err != nil {
+		log.Errorf("Failed to create audit log (non-blocking): %v", err)
+	}
+
+	log.Infof("Legal hold placed on campaign %d by user %d: %s", campaignID, userID, reason)
+	return tx.Commit().Error
+}
+
+// ReleaseLegalHold clears a legal hold placed by PlaceLegalHold, restoring
+// campaignID to normal TTL/purge eligibility. Restricted to admins.
+func ReleaseLegalHold(campaignID int64, userID int64, isAdmin bool) error {
+	if !isAdmin {
+		return errors.New("legal hold requires admin privileges")
+	}
+
+	tx := db.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	c := &Campaign{}
+	if err := lockForUpdate(tx).Unscoped().First(c, campaignID).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCampaignNotFound
+		}
+		return err
+	}
+
+	c.LegalHold = false
+	c.LegalHoldReason = ""
+	c.LegalHoldBy = nil
+	c.LegalHoldAt = nil
+
+	if err := tx.Unscoped().Save(c).Error; err != nil {
+		tx.Rollback()
+		log.Errorf("Failed to release legal hold on campaign %d: %v", campaignID, err)
+		return err
+	}
+
+	audit := &AuditLog{
+		ActorID:    &userID,
+		Action:     AuditLegalHoldReleased,
+		EntityType: "campaign",
+		EntityID:   campaignID,
+	}
+	audit.SetMetadata(map[string]interface{}{
+		"name": c.Name,
+	})
+	if err := tx.Create(audit).Error; err != nil {
+		log.Errorf("Failed to create audit log (non-blocking): %v", err)
+	}
+
+	log.Infof("Legal hold released on campaign %d by user %d", campaignID, userID)
+	return tx.Commit().Error
+}
+
 // checkNameConflict checks if campaign name conflicts with active campaigns
 func checkNameConflict(tx *gorm.DB, name string, userID int64, excludeID int64) (bool, error) {
 	var count int64
@@ -424,6 +572,7 @@ func ListPurgeCandidates(cutoff time.Time, limit int) ([]int64, error) {
 
 	err := db.Table("campaigns").
 		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Where("legal_hold = ? OR legal_hold IS NULL", false).
 		Order("deleted_at ASC"). // Purge oldest first
 		Limit(limit).
 		Pluck("id", &ids).Error
@@ -436,6 +585,20 @@ func ListPurgeCandidates(cutoff time.Time, limit int) ([]int64, error) {
 	return ids, nil
 }
 
+// AnyCampaignInProgress reports whether any of the given campaign ids are
+// currently CampaignInProgress, so a bulk purge can be refused up front
+// instead of failing (or worse, purging) partway through a live send.
+func AnyCampaignInProgress(ids []int64) (bool, error) {
+	if len(ids) == 0 {
+		return false, nil
+	}
+	var count int64
+	err := db.Unscoped().Model(&Campaign{}).
+		Where("id IN (?) AND status = ?", ids, CampaignInProgress).
+		Count(&count).Error
+	return count > 0, err
+}
+
 // PurgeSystemCampaign is a system-level purge (bypasses user permission checks)
 // Used by TTL job. Still requires campaign to be in trash.
 func PurgeSystemCampaign(campaignID int64) error {
@@ -469,6 +632,14 @@ func PurgeSystemCampaign(campaignID int64) error {
 		return nil // No-op, campaign was restored
 	}
 
+	// Re-check legal hold under the row lock - it may have been placed
+	// after ListPurgeCandidates ran but before this purge acquired the lock.
+	if c.LegalHold {
+		tx.Rollback()
+		log.Warnf("Campaign %d was placed under legal hold before system purge, skipping", campaignID)
+		return ErrLegalHold
+	}
+
 	// Audit BEFORE delete
 	audit := &AuditLog{
 		ActorID:    nil, // System actor
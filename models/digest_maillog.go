@@ -0,0 +1,140 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	log "github.com/gophish/gophish/logger"
+)
+
+// DigestItem is a single entry bundled into a digest email - a result from a
+// child campaign, a landing page visit, or a calendar event - rendered via
+// the template's repeater block over PhishingTemplateContext.Items.
+type DigestItem struct {
+	ID         int64     `json:"id"`
+	Type       string    `json:"type"` // campaign, landing_page, calendar_event
+	Title      string    `json:"title"`
+	URL        string    `json:"url"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// CollectDigestItems gathers the calendar events recorded for a result since
+// windowStart, which is the first digest source: a running calendar
+// campaign's link opens, credential submissions, and RSVPs. Additional item
+// sources (child campaigns, landing pages) plug in the same way as the
+// digest feature grows to cover them.
+func CollectDigestItems(c *Campaign, r *Result, windowStart time.Time) ([]DigestItem, error) {
+	events, err := GetCalendarEventsByResult(r.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]DigestItem, 0, len(events))
+	for _, e := range events {
+		if e.Timestamp.Before(windowStart) {
+			continue
+		}
+		items = append(items, DigestItem{
+			ID:         e.Id,
+			Type:       "calendar_event",
+			Title:      e.EventType,
+			URL:        fmt.Sprintf("%s/calendar?rid=%s", c.URL, r.RId),
+			OccurredAt: e.Timestamp,
+		})
+	}
+	return items, nil
+}
+
+// GenerateDigestMailLog queues a single summary maillog bundling the digest
+// items collected for a result over the campaign's configured window, the
+// same way GenerateMailLog queues a regular campaign's maillog - the body
+// itself isn't rendered until the worker picks it up and calls
+// MailLog.Generate, which is why items are persisted onto the maillog via
+// SetDigestItems rather than rendered here. It also records a
+// CalendarEvent-style audit row per included item so per-item click
+// attribution survives the bundling.
+func GenerateDigestMailLog(c *Campaign, r *Result, windowStart time.Time) error {
+	items, err := CollectDigestItems(c, r, windowStart)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		log.Debugf("Digest: no items for result %s in campaign %d, skipping send", r.RId, c.Id)
+		return nil
+	}
+
+	m := &MailLog{
+		UserId:     c.UserId,
+		CampaignId: c.Id,
+		RId:        r.RId,
+		SendDate:   time.Now().UTC(),
+	}
+	if err := m.SetDigestItems(items); err != nil {
+		return err
+	}
+	m.CacheCampaign(c)
+	if err := db.Save(m).Error; err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		audit := &CalendarEvent{
+			ResultId:  r.Id,
+			EventType: "digest_item_included",
+			Details:   fmt.Sprintf(`{"item_id":%d,"item_type":%q,"title":%q}`, item.ID, item.Type, item.Title),
+		}
+		if err := SaveCalendarEvent(audit); err != nil {
+			log.Warnf("Digest: failed to record audit row for item %d: %v", item.ID, err)
+		}
+	}
+
+	log.Infof("Digest: queued maillog %d for result %s with %d item(s)", m.Id, r.RId, len(items))
+	return nil
+}
+
+// DigestCampaignRef identifies a running digest campaign, as returned by
+// ListActiveDigestCampaigns - just enough for DigestJob to load the full
+// campaign via GetCampaignMailContext before calling GenerateDigestMailLog.
+// CronExpr is read straight off the campaigns table (digest_cron_expr
+// column) rather than a Campaign struct field, the same way the rest of
+// this file queries campaigns/results columns it doesn't otherwise model;
+// it's empty for a digest campaign that hasn't set its own schedule, in
+// which case DigestJob falls back to its configured default cadence
+// (DigestConfig.Interval).
+type DigestCampaignRef struct {
+	ID       int64
+	UserID   int64
+	CronExpr string
+}
+
+// ListActiveDigestCampaigns returns every currently-running campaign with
+// campaign_type = "digest", the set DigestJob walks on each pass.
+func ListActiveDigestCampaigns() ([]DigestCampaignRef, error) {
+	if db == nil {
+		return nil, errors.New("database not initialized")
+	}
+
+	var refs []DigestCampaignRef
+	err := db.Table("campaigns").
+		Select("id, user_id, digest_cron_expr as cron_expr").
+		Where("campaign_type = ? AND status = ?", "digest", CampaignInProgress).
+		Scan(&refs).Error
+	if err != nil {
+		log.Errorf("Error listing active digest campaigns: %v", err)
+		return nil, err
+	}
+	return refs, nil
+}
+
+// ResultRIDsForCampaign returns the RId of every result recorded for
+// campaignID, so DigestJob can call GenerateDigestMailLog once per recipient.
+func ResultRIDsForCampaign(campaignID int64) ([]string, error) {
+	var rids []string
+	err := db.Table("results").Where("campaign_id = ?", campaignID).Pluck("r_id", &rids).Error
+	if err != nil {
+		log.Errorf("Error listing results for campaign %d: %v", campaignID, err)
+		return nil, err
+	}
+	return rids, nil
+}
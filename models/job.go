@@ -0,0 +1,158 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	log "github.com/gophish/gophish/logger"
+)
+
+// Job state values.
+const (
+	JobStateProcessing = "processing"
+	JobStateComplete   = "complete"
+	JobStateFailed     = "failed"
+)
+
+// Job is a record of a long-running admin operation (campaign purge,
+// restore, bulk trash action, ...) that's too slow to run inline with its
+// triggering HTTP request. The API enqueues a Job and responds
+// 202 Accepted with a Location pointing at GET /api/jobs/{id}, so the UI
+// can poll a single endpoint regardless of what kind of work is running.
+type Job struct {
+	Id        string    `json:"id" gorm:"primaryKey"`
+	Type      string    `json:"type"`
+	State     string    `json:"state"`
+	Errors    string    `json:"-" gorm:"type:text"`
+	Result    string    `json:"-" gorm:"type:text"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for Job
+func (Job) TableName() string {
+	return "jobs"
+}
+
+// NewJob builds a Job with a typed GUID of the form
+// "<jobType>.<entityID>.<token>" (e.g. "purge.42.9f1c2b..."), so a
+// consumer of the id alone can tell what kind of job it's looking at and
+// what it operated on before ever fetching the record.
+func NewJob(jobType string, entityID int64) *Job {
+	now := time.Now().UTC()
+	return &Job{
+		Id:        fmt.Sprintf("%s.%d.%s", jobType, entityID, generateJobToken()),
+		Type:      jobType,
+		State:     JobStateProcessing,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func generateJobToken() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand failing is effectively impossible; fall back to a
+		// timestamp so we never hand out a colliding id.
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// SetErrors serializes a slice of error strings onto the job.
+func (j *Job) SetErrors(errs []string) error {
+	if len(errs) == 0 {
+		j.Errors = ""
+		return nil
+	}
+	data, err := json.Marshal(errs)
+	if err != nil {
+		return err
+	}
+	j.Errors = string(data)
+	return nil
+}
+
+// GetErrors deserializes the job's stored errors back into a slice.
+func (j *Job) GetErrors() ([]string, error) {
+	if j.Errors == "" {
+		return nil, nil
+	}
+	errs := []string{}
+	err := json.Unmarshal([]byte(j.Errors), &errs)
+	return errs, err
+}
+
+// SetResult serializes the job's result payload (e.g. the restored
+// campaign's id and warnings, or nothing for a purge).
+func (j *Job) SetResult(result map[string]interface{}) error {
+	if result == nil {
+		j.Result = ""
+		return nil
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	j.Result = string(data)
+	return nil
+}
+
+// GetResult deserializes the job's stored result back into a map.
+func (j *Job) GetResult() (map[string]interface{}, error) {
+	if j.Result == "" {
+		return nil, nil
+	}
+	result := map[string]interface{}{}
+	err := json.Unmarshal([]byte(j.Result), &result)
+	return result, err
+}
+
+// CreateJob persists a newly-enqueued job.
+func CreateJob(job *Job) error {
+	if db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	if err := db.Create(job).Error; err != nil {
+		log.Errorf("Failed to create job %s: %v", job.Id, err)
+		return err
+	}
+	return nil
+}
+
+// GetJob returns a single job by its typed GUID.
+func GetJob(id string) (*Job, error) {
+	job := &Job{}
+	if err := db.Where("id = ?", id).First(job).Error; err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// CompleteJob flushes the final state, result, and errors of a job once its
+// handler returns.
+func CompleteJob(id string, state string, result map[string]interface{}, errs []string) error {
+	job, err := GetJob(id)
+	if err != nil {
+		log.Errorf("Failed to load job %s to complete it: %v", id, err)
+		return err
+	}
+
+	job.State = state
+	job.UpdatedAt = time.Now().UTC()
+	if err := job.SetResult(result); err != nil {
+		return err
+	}
+	if err := job.SetErrors(errs); err != nil {
+		return err
+	}
+
+	if err := db.Save(job).Error; err != nil {
+		log.Errorf("Failed to complete job %s: %v", id, err)
+		return err
+	}
+	return nil
+}
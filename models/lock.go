@@ -0,0 +1,114 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Lock is a simple distributed mutual-exclusion lease, used to stop more
+// than one ChronoLure replica from running the same scheduled job (e.g.
+// TrashTTLJob) at the same time. A lock is free whenever its row is
+// missing or its ExpiresAt has passed; AcquireLock atomically claims it
+// for Owner until ExpiresAt.
+type Lock struct {
+	Name       string    `json:"name" gorm:"primary_key"`
+	Owner      string    `json:"owner"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// TableName specifies the table name for Lock
+func (Lock) TableName() string {
+	return "locks"
+}
+
+// AcquireLock claims name for owner until ttl elapses, succeeding only if
+// the lock row doesn't exist yet or its previous lease has expired. The
+// claim is a single atomic upsert rather than a SELECT-then-write: a
+// transaction wrapped around lockForUpdate only serializes concurrent
+// claims on MySQL/PostgreSQL, since lockForUpdate is a no-op on SQLite,
+// which let two replicas both observe the lock as free and both write.
+func AcquireLock(name string, ttl time.Duration, owner string) (bool, error) {
+	if db == nil {
+		return false, errors.New("database not initialized")
+	}
+
+	now := time.Now().UTC()
+	rowsAffected, err := upsertLock(name, owner, now, now.Add(ttl))
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+// upsertLock claims name for owner in a single atomic statement: insert it
+// fresh, or overwrite an existing row only if its lease has already
+// expired. Postgres and SQLite share the same "ON CONFLICT DO UPDATE ...
+// WHERE" upsert syntax; MySQL has no WHERE-guarded upsert, so it's emulated
+// with IF() guards inside ON DUPLICATE KEY UPDATE, which also makes MySQL
+// report 0 rows affected when every guard left the row unchanged (lock
+// still held) the same way the WHERE clause does for the other two.
+func upsertLock(name, owner string, acquiredAt, expiresAt time.Time) (int64, error) {
+	if db.Dialect().GetName() == "mysql" {
+		result := db.Exec(
+			`INSERT INTO locks (name, owner, acquired_at, expires_at) VALUES (?, ?, ?, ?)
+			 ON DUPLICATE KEY UPDATE
+			   owner = IF(expires_at < ?, VALUES(owner), owner),
+			   acquired_at = IF(expires_at < ?, VALUES(acquired_at), acquired_at),
+			   expires_at = IF(expires_at < ?, VALUES(expires_at), expires_at)`,
+			name, owner, acquiredAt, expiresAt,
+			acquiredAt, acquiredAt, acquiredAt,
+		)
+		return result.RowsAffected, result.Error
+	}
+
+	result := db.Exec(
+		`INSERT INTO locks (name, owner, acquired_at, expires_at) VALUES (?, ?, ?, ?)
+		 ON CONFLICT (name) DO UPDATE SET owner = ?, acquired_at = ?, expires_at = ?
+		 WHERE locks.expires_at < ?`,
+		name, owner, acquiredAt, expiresAt,
+		owner, acquiredAt, expiresAt,
+		acquiredAt,
+	)
+	return result.RowsAffected, result.Error
+}
+
+// RenewLock extends owner's existing lease on name so it expires ttl from
+// now, returning false
+// (without error) if owner no longer holds the lock - e.g. it expired and
+// was claimed by another replica in the meantime.
+func RenewLock(name string, ttl time.Duration, owner string) (bool, error) {
+	if db == nil {
+		return false, errors.New("database not initialized")
+	}
+	result := db.Model(&Lock{}).
+		Where("name = ? AND owner = ?", name, owner).
+		Update("expires_at", time.Now().UTC().Add(ttl))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ReleaseLock drops owner's lease on name, so another replica can claim it
+// immediately instead of waiting out the ttl.
+func ReleaseLock(name string, owner string) error {
+	if db == nil {
+		return errors.New("database not initialized")
+	}
+	return db.Where("name = ? AND owner = ?", name, owner).Delete(&Lock{}).Error
+}
+
+// GetLock returns the current lock row for name, or gorm.ErrRecordNotFound
+// if no row exists. A row whose lease has expired is still returned. Used
+// to surface the current owner/expiry via GetMetrics.
+func GetLock(name string) (*Lock, error) {
+	if db == nil {
+		return nil, errors.New("database not initialized")
+	}
+	lock := &Lock{}
+	if err := db.Where("name = ?", name).First(lock).Error; err != nil {
+		return nil, err
+	}
+	return lock, nil
+}
@@ -3,11 +3,13 @@ package models
 import (
 	"crypto/rand"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"math"
 	"math/big"
+	mrand "math/rand"
 	"net/mail"
 	"os"
 	"path/filepath"
@@ -21,8 +23,8 @@ import (
 	"github.com/gophish/gophish/mailer"
 )
 
-// MaxSendAttempts set to 8 since we exponentially backoff after each failed send
-// attempt. This will give us a maximum send delay of 256 minutes, or about 4.2 hours.
+// MaxSendAttempts is the fallback retry ceiling used when a campaign's SMTP
+// profile doesn't set its own SMTP.MaxSendAttempts.
 var MaxSendAttempts = 8
 
 // ErrMaxSendAttempts is thrown when the maximum number of sending attempts for a given
@@ -42,10 +44,27 @@ type MailLog struct {
 	SendDate    time.Time `json:"send_date"`
 	SendAttempt int       `json:"send_attempt"`
 	Processing  bool      `json:"-"`
+	// PrevDelaySeconds is the delay Backoff computed on the last attempt.
+	// Persisting it lets decorrelated jitter pick up where it left off
+	// across restarts instead of resetting to the base delay.
+	PrevDelaySeconds int `json:"-"`
+	// DigestItems is the JSON-encoded []DigestItem a digest campaign's
+	// GenerateDigestMailLog bundled for this recipient. Generate reads it
+	// back at send time and renders it into PhishingTemplateContext.Items,
+	// since (like every other MailLog) the body itself isn't rendered until
+	// send time.
+	DigestItems string `json:"-" gorm:"type:text"`
 
 	cachedCampaign *Campaign
 }
 
+// Default decorrelated-jitter backoff bounds, used when a campaign's SMTP
+// profile doesn't specify its own BackoffBase/BackoffCap.
+const (
+	defaultBackoffBase = 1 * time.Minute
+	defaultBackoffCap  = 256 * time.Minute
+)
+
 // GenerateMailLog creates a new maillog for the given campaign and
 // result. It sets the initial send date to match the campaign's launch date.
 func GenerateMailLog(c *Campaign, r *Result, sendDate time.Time) error {
@@ -55,27 +74,84 @@ func GenerateMailLog(c *Campaign, r *Result, sendDate time.Time) error {
 		RId:        r.RId,
 		SendDate:   sendDate,
 	}
-	return db.Save(m).Error
+	if err := db.Save(m).Error; err != nil {
+		return err
+	}
+
+	// Calendar campaigns with a recurrence rule schedule one follow-up
+	// maillog per recurrence instance, so re-invites/updates go out without
+	// operator intervention as the series progresses.
+	if c.CampaignType == "calendar" && c.RecurrenceRule != "" {
+		return scheduleRecurrenceMailLogs(c, r, sendDate)
+	}
+	return nil
 }
 
-// Backoff sets the MailLog SendDate to be the next entry in an exponential
-// backoff. ErrMaxRetriesExceeded is thrown if this maillog has been retried
-// too many times. Backoff also unlocks the maillog so that it can be processed
-// again in the future.
+// scheduleRecurrenceMailLogs queues a follow-up MailLog for each occurrence
+// expanded from the campaign's RRULE, skipping any date in ExceptionDates.
+func scheduleRecurrenceMailLogs(c *Campaign, r *Result, firstSendDate time.Time) error {
+	occurrences, err := expandRecurrence(c.RecurrenceRule, c.EventStartTime, c.ExceptionDates)
+	if err != nil {
+		log.Warnf("Unable to expand RRULE %q for campaign %d: %v", c.RecurrenceRule, c.Id, err)
+		return nil
+	}
+
+	for _, occurrence := range occurrences {
+		// The first occurrence is covered by the maillog created above.
+		if !occurrence.After(c.EventStartTime) {
+			continue
+		}
+		followUp := &MailLog{
+			UserId:     c.UserId,
+			CampaignId: c.Id,
+			RId:        r.RId,
+			SendDate:   firstSendDate.Add(occurrence.Sub(c.EventStartTime)),
+		}
+		if err := db.Save(followUp).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Backoff sets the MailLog SendDate to be the next entry in a decorrelated
+// jitter backoff (sleep = min(cap, random_between(base, prev*3))), which
+// spreads retries out instead of having an entire batch hammer the same
+// relay at the same deterministic instants after a transient failure (e.g.
+// greylisting). ErrMaxSendAttempts is thrown if this maillog has been
+// retried too many times. Backoff also unlocks the maillog so that it can be
+// processed again in the future.
 func (m *MailLog) Backoff(reason error) error {
 	r, err := GetResult(m.RId)
 	if err != nil {
 		return err
 	}
-	if m.SendAttempt == MaxSendAttempts {
+
+	maxAttempts := MaxSendAttempts
+	base := defaultBackoffBase
+	cap := defaultBackoffCap
+	if c, err := m.getCampaign(); err == nil {
+		if c.SMTP.MaxSendAttempts > 0 {
+			maxAttempts = c.SMTP.MaxSendAttempts
+		}
+		if c.SMTP.BackoffBase > 0 {
+			base = c.SMTP.BackoffBase
+		}
+		if c.SMTP.BackoffCap > 0 {
+			cap = c.SMTP.BackoffCap
+		}
+	}
+
+	if m.SendAttempt == maxAttempts {
 		r.HandleEmailError(ErrMaxSendAttempts)
 		return ErrMaxSendAttempts
 	}
 	// Add an error, since we had to backoff because of a
 	// temporary error of some sort during the SMTP transaction
 	m.SendAttempt++
-	backoffDuration := math.Pow(2, float64(m.SendAttempt))
-	m.SendDate = m.SendDate.Add(time.Minute * time.Duration(backoffDuration))
+	delay := decorrelatedJitter(base, cap, time.Duration(m.PrevDelaySeconds)*time.Second)
+	m.PrevDelaySeconds = int(delay.Seconds())
+	m.SendDate = m.SendDate.Add(delay)
 	err = db.Save(m).Error
 	if err != nil {
 		return err
@@ -135,17 +211,49 @@ func (m *MailLog) Success() error {
 
 // GetDialer returns a dialer based on the maillog campaign's SMTP configuration
 func (m *MailLog) GetDialer() (mailer.Dialer, error) {
-	c := m.cachedCampaign
-	if c == nil {
-		campaign, err := GetCampaignMailContext(m.CampaignId, m.UserId)
-		if err != nil {
-			return nil, err
-		}
-		c = &campaign
+	c, err := m.getCampaign()
+	if err != nil {
+		return nil, err
 	}
 	return c.SMTP.GetDialer()
 }
 
+// getCampaign returns the maillog's campaign, preferring the cached copy
+// set via CacheCampaign to avoid repeated lookups during bulk sends.
+func (m *MailLog) getCampaign() (*Campaign, error) {
+	if m.cachedCampaign != nil {
+		return m.cachedCampaign, nil
+	}
+	campaign, err := GetCampaignMailContext(m.CampaignId, m.UserId)
+	if err != nil {
+		return nil, err
+	}
+	return &campaign, nil
+}
+
+// decorrelatedJitter implements the AWS "decorrelated jitter" backoff
+// algorithm: sleep = min(cap, random_between(base, prev*3)). Using prev*3 as
+// the upper bound (rather than a fixed exponential schedule) means retries
+// across a batch of maillogs naturally spread out instead of all waking up
+// at the same deterministic 2^attempt instant.
+func decorrelatedJitter(base, cap, prev time.Duration) time.Duration {
+	upper := prev * 3
+	if upper < base {
+		upper = base
+	}
+	if upper > cap {
+		upper = cap
+	}
+	if upper <= base {
+		return base
+	}
+	delay := base + time.Duration(mrand.Int63n(int64(upper-base)))
+	if delay > cap {
+		delay = cap
+	}
+	return delay
+}
+
 // CacheCampaign allows bulk-mail workers to cache the otherwise expensive
 // campaign lookup operation by providing a pointer to the campaign here.
 func (m *MailLog) CacheCampaign(campaign *Campaign) error {
@@ -156,6 +264,28 @@ func (m *MailLog) CacheCampaign(campaign *Campaign) error {
 	return nil
 }
 
+// SetDigestItems serializes items onto the maillog so Generate can render
+// them into PhishingTemplateContext.Items at send time.
+func (m *MailLog) SetDigestItems(items []DigestItem) error {
+	data, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	m.DigestItems = string(data)
+	return nil
+}
+
+// GetDigestItems deserializes the items SetDigestItems stored, or nil if
+// none were set (a non-digest campaign's maillog).
+func (m *MailLog) GetDigestItems() ([]DigestItem, error) {
+	if m.DigestItems == "" {
+		return nil, nil
+	}
+	var items []DigestItem
+	err := json.Unmarshal([]byte(m.DigestItems), &items)
+	return items, err
+}
+
 func (m *MailLog) GetSmtpFrom() (string, error) {
 	c, err := GetCampaign(m.CampaignId, m.UserId)
 	if err != nil {
@@ -204,6 +334,16 @@ func (m *MailLog) Generate(msg *gomail.Message) error {
 		return err
 	}
 
+	// Digest campaigns bundle several DigestItems into one email; render
+	// them into the template's repeater block via ptx.Items.
+	if c.CampaignType == "digest" {
+		items, err := m.GetDigestItems()
+		if err != nil {
+			log.Warnf("Failed to decode digest items for maillog %d: %v", m.Id, err)
+		}
+		ptx.Items = items
+	}
+
 	// Add the transparency headers
 	msg.SetHeader("X-Mailer", config.ServerName)
 	if conf.ContactAddress != "" {
@@ -316,12 +456,19 @@ func (m *MailLog) generateCalendarEmail(msg *gomail.Message, r *Result, c *Campa
 		msg.SetHeader("X-Gophish-Contact", conf.ContactAddress)
 	}
 
-	// Add Message-Id header
+	// Add Message-Id header. Recurring/re-sent invites share the same
+	// thread root (derived from the recipient's RId, like the calendar UID) via the
+	// References header, so Outlook groups updates with the original invite
+	// instead of treating each re-send as a new conversation.
+	threadRoot := m.generateThreadRootID(r.RId)
 	messageID, err := m.generateMessageID()
 	if err != nil {
 		return err
 	}
 	msg.SetHeader("Message-Id", messageID)
+	if messageID != threadRoot {
+		msg.SetHeader("References", threadRoot)
+	}
 
 	// Parse custom headers
 	for _, header := range c.SMTP.Headers {
@@ -391,9 +538,18 @@ func (m *MailLog) generateCalendarEmail(msg *gomail.Message, r *Result, c *Campa
 	// Calculate end time
 	endTime := c.EventStartTime.Add(time.Duration(c.EventDuration) * time.Minute)
 
+	// SEQUENCE increments on every re-send of the same invite (e.g. a
+	// recurring campaign's follow-up mail logs) so Outlook treats later
+	// messages as updates to the original meeting rather than duplicates.
+	sequence, err := CountCalendarEventsByResultAndType(r.Id, "ics_sent")
+	if err != nil {
+		sequence = 0
+	}
+
 	// Generate .ICS file content
 	// IMPORTANT: Location field uses tracking URL (/calendar?rid=...) for proper tracking
 	// The MeetingURL field in description will also show the same URL
+	organizerName, organizerEmail, timezone, attendees := resolveCalendarInvite(c, r)
 	calEvent := ics.CalendarEvent{
 		UID:             fmt.Sprintf("gophish-%s@%s", r.RId, "gophish.local"),
 		Title:           eventTitle,
@@ -401,15 +557,31 @@ func (m *MailLog) generateCalendarEmail(msg *gomail.Message, r *Result, c *Campa
 		Location:        meetingURL,
 		StartTime:       c.EventStartTime,
 		EndTime:         endTime,
-		OrganizerName:   c.OrganizerName,
-		OrganizerEmail:  c.OrganizerEmail,
-		AttendeeName:    r.FormatName(),
-		AttendeeEmail:   r.Email,
+		OrganizerName:   organizerName,
+		OrganizerEmail:  organizerEmail,
+		Attendees:       attendees,
 		ReminderMinutes: 15,
 		MeetingURL:      meetingURL,
+		RecurrenceRule:  c.RecurrenceRule,
+		ExceptionDates:  c.ExceptionDates,
+		Timezone:        timezone,
+		Sequence:        sequence,
 	}
+	calEvent.Method = "REQUEST"
 	icsContent := calEvent.Generate()
 
+	// Inline the invite as a text/calendar;method=REQUEST alternative so
+	// Outlook/Gmail/Apple Mail render native Accept/Decline controls instead
+	// of treating the invite as a plain file attachment. This mirrors the
+	// ORGANIZER/ATTENDEE lines written into the attached copy below, which is
+	// kept for clients (and calendar import flows) that only look at
+	// attachments.
+	msg.AddAlternative("text/calendar; method=REQUEST; charset=utf-8", icsContent)
+
+	// Content-Class tells Outlook to treat the whole message as a meeting
+	// request rather than a plain email with a calendar attachment.
+	msg.SetHeader("Content-Class", "urn:content-classes:calendarmessage")
+
 	// Attach .ICS file with proper MIME type for Outlook
 	msg.Attach(fmt.Sprintf("meeting_%s.ics", r.RId),
 		gomail.SetCopyFunc(func(w io.Writer) error {
@@ -428,21 +600,46 @@ func (m *MailLog) generateCalendarEmail(msg *gomail.Message, r *Result, c *Campa
 		EventType: "ics_sent",
 		Timestamp: time.Now().UTC(),
 		Details:   fmt.Sprintf(`{"event_title":"%s","start_time":"%s"}`, processedEventTitle, c.EventStartTime.Format(time.RFC3339)),
+		Sequence:  sequence,
 	}
 	SaveCalendarEvent(calEventLog)
 
 	return nil
 }
 
-// GetQueuedMailLogs returns the mail logs that are queued up for the given minute.
+// GetQueuedMailLogs returns the mail logs that are queued up for the given
+// minute. MailLogs whose campaign's SMTP profile is currently over its
+// configured send rate are left in the queue for a later pass, so a single
+// slow relay doesn't starve other campaigns sharing the worker loop.
 func GetQueuedMailLogs(t time.Time) ([]*MailLog, error) {
 	ms := []*MailLog{}
 	err := db.Where("send_date <= ? AND processing = ?", t, false).
 		Find(&ms).Error
 	if err != nil {
 		log.Warn(err)
+		return ms, err
 	}
-	return ms, err
+
+	allowed := make([]*MailLog, 0, len(ms))
+	smtpCache := map[int64]*Campaign{}
+	for _, m := range ms {
+		c, ok := smtpCache[m.CampaignId]
+		if !ok {
+			campaign, err := GetCampaignMailContext(m.CampaignId, m.UserId)
+			if err != nil {
+				// Can't resolve the SMTP profile - don't block the send on
+				// a rate limiter we can't evaluate.
+				allowed = append(allowed, m)
+				continue
+			}
+			c = &campaign
+			smtpCache[m.CampaignId] = c
+		}
+		if allowSMTPSend(c.SMTP.Id, c.SMTP.RateLimitPerSecond) {
+			allowed = append(allowed, m)
+		}
+	}
+	return allowed, nil
 }
 
 // GetMailLogsByCampaign returns all of the mail logs for a given campaign.
@@ -501,6 +698,18 @@ func (m *MailLog) generateMessageID() (string, error) {
 	return msgid, nil
 }
 
+// generateThreadRootID returns a stable Message-Id derived from the
+// recipient's RId, suitable for use as the first entry in a References
+// header. Unlike generateMessageID, this is deterministic across calls so
+// every re-invite/update for the same recurring meeting threads together.
+func (m *MailLog) generateThreadRootID(rid string) string {
+	h, err := os.Hostname()
+	if err != nil {
+		h = "localhost.localdomain"
+	}
+	return fmt.Sprintf("<%s@%s>", rid, h)
+}
+
 // Check if an attachment should have inline disposition based on
 // its file extension.
 func shouldEmbedAttachment(name string) bool {
@@ -567,6 +776,7 @@ func GenerateICSForResult(r *Result, c *Campaign) (string, error) {
 	// Generate .ICS file content
 	// IMPORTANT: Location field uses tracking URL (/calendar?rid=...) for proper tracking
 	// The MeetingURL field in description will also show the same URL
+	organizerName, organizerEmail, timezone, attendees := resolveCalendarInvite(c, r)
 	calEvent := ics.CalendarEvent{
 		UID:             fmt.Sprintf("gophish-%s@%s", r.RId, "gophish.local"),
 		Title:           eventTitle,
@@ -574,12 +784,17 @@ func GenerateICSForResult(r *Result, c *Campaign) (string, error) {
 		Location:        meetingURL,
 		StartTime:       c.EventStartTime,
 		EndTime:         endTime,
-		OrganizerName:   c.OrganizerName,
-		OrganizerEmail:  c.OrganizerEmail,
-		AttendeeName:    r.FormatName(),
-		AttendeeEmail:   r.Email,
+		OrganizerName:   organizerName,
+		OrganizerEmail:  organizerEmail,
+		Attendees:       attendees,
 		ReminderMinutes: 15,
 		MeetingURL:      meetingURL,
+		RecurrenceRule:  c.RecurrenceRule,
+		ExceptionDates:  c.ExceptionDates,
+		Timezone:        timezone,
+	}
+	if sequence, err := CountCalendarEventsByResultAndType(r.Id, "ics_sent"); err == nil {
+		calEvent.Sequence = sequence
 	}
 
 	return calEvent.Generate(), nil
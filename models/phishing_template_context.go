@@ -0,0 +1,78 @@
+package models
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+)
+
+// PhishingTemplateContext is the data a campaign's email/page templates
+// render against: the recipient being sent to, the generated tracking
+// URL/RId, and the extra fields specific campaign types populate on top of
+// that base context (calendar invite details, digest items).
+type PhishingTemplateContext struct {
+	BaseRecipient
+	URL         string
+	TrackingURL string
+	RId         string
+
+	// Calendar campaign fields, populated by generateCalendarEmail once the
+	// event title/description templates have themselves been rendered.
+	EventTitle       string
+	EventDescription string
+	EventStartTime   string
+	EventDuration    int
+	OrganizerName    string
+	OrganizerEmail   string
+
+	// Items is the digest items bundled into a digest campaign's send (see
+	// DigestItem), populated by MailLog.Generate from the maillog's stored
+	// DigestItems. Nil for every other campaign type.
+	Items []DigestItem
+}
+
+// NewPhishingTemplateContext builds the base template context for a send to
+// r: the tracking URL a click/open is attributed back to via rid, alongside
+// the recipient fields templates reference directly (e.g. {{.FirstName}}).
+func NewPhishingTemplateContext(c *Campaign, r BaseRecipient, rid string) (PhishingTemplateContext, error) {
+	trackingURL := fmt.Sprintf("%s/track?rid=%s", c.URL, rid)
+	return PhishingTemplateContext{
+		BaseRecipient: r,
+		URL:           fmt.Sprintf("%s?rid=%s", c.URL, rid),
+		TrackingURL:   trackingURL,
+		RId:           rid,
+	}, nil
+}
+
+// digestTemplateFuncs are the template functions a digest campaign's
+// repeater block can call to iterate PhishingTemplateContext.Items, so a
+// template author doesn't have to hand-roll the grouping logic to render
+// one section per item type (calendar events, landing page visits, ...).
+var digestTemplateFuncs = template.FuncMap{
+	"groupDigestItemsByType": groupDigestItemsByType,
+}
+
+// groupDigestItemsByType buckets items by their Type field, preserving each
+// group's first-seen order, so {{range groupDigestItemsByType .Items}}
+// can render one section per item type instead of a single flat list.
+func groupDigestItemsByType(items []DigestItem) map[string][]DigestItem {
+	groups := make(map[string][]DigestItem)
+	for _, item := range items {
+		groups[item.Type] = append(groups[item.Type], item)
+	}
+	return groups
+}
+
+// ExecuteTemplate renders text against ptx, exposing digestTemplateFuncs so
+// a digest campaign's template can iterate ptx.Items.
+func ExecuteTemplate(text string, ptx PhishingTemplateContext) (string, error) {
+	tmpl, err := template.New("template").Funcs(digestTemplateFuncs).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, ptx); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
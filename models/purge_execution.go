@@ -0,0 +1,176 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	log "github.com/gophish/gophish/logger"
+)
+
+// Purge execution trigger and status values.
+const (
+	PurgeTriggerScheduled = "scheduled"
+	PurgeTriggerManual    = "manual"
+
+	PurgeStatusRunning = "running"
+	PurgeStatusSuccess = "success"
+	PurgeStatusError   = "error"
+	PurgeStatusStopped = "stopped"
+)
+
+// PurgeExecution is a first-class record of a single TrashTTLJob.RunOnce
+// invocation, so admins can audit what the TTL job actually did without
+// scraping stdout.
+type PurgeExecution struct {
+	Id              int64      `json:"id" gorm:"primaryKey"`
+	Trigger         string     `json:"trigger"` // scheduled, manual
+	StartTime       time.Time  `json:"start_time"`
+	EndTime         *time.Time `json:"end_time,omitempty"`
+	Status          string     `json:"status"` // running, success, error, stopped
+	CandidatesFound int        `json:"candidates_found"`
+	Succeeded       int        `json:"succeeded"`
+	Failed          int        `json:"failed"`
+	CutoffTime      time.Time  `json:"cutoff_time"`
+	Details         string     `json:"details,omitempty" gorm:"type:text"`
+}
+
+// TableName specifies the table name for PurgeExecution
+func (PurgeExecution) TableName() string {
+	return "purge_executions"
+}
+
+// PurgeExecutionItem records the per-campaign outcome of a single
+// PurgeExecution.
+type PurgeExecutionItem struct {
+	Id           int64  `json:"id" gorm:"primaryKey"`
+	ExecutionId  int64  `json:"execution_id"`
+	CampaignId   int64  `json:"campaign_id"`
+	Success      bool   `json:"success"`
+	ErrorMessage string `json:"error_message,omitempty"`
+}
+
+// TableName specifies the table name for PurgeExecutionItem
+func (PurgeExecutionItem) TableName() string {
+	return "purge_execution_items"
+}
+
+// CreatePurgeExecution inserts a running PurgeExecution row at the start of
+// a RunOnce invocation and returns it so the caller can fill in the outcome
+// once the batch completes.
+func CreatePurgeExecution(trigger string, cutoff time.Time) (*PurgeExecution, error) {
+	if db == nil {
+		return nil, errors.New("database not initialized")
+	}
+	exec := &PurgeExecution{
+		Trigger:    trigger,
+		StartTime:  time.Now().UTC(),
+		Status:     PurgeStatusRunning,
+		CutoffTime: cutoff,
+	}
+	if err := db.Create(exec).Error; err != nil {
+		log.Errorf("Failed to create purge execution: %v", err)
+		return nil, err
+	}
+	return exec, nil
+}
+
+// CompletePurgeExecution flushes the final outcome of a RunOnce invocation,
+// along with the per-campaign item rows gathered along the way.
+func CompletePurgeExecution(exec *PurgeExecution, status string, items []PurgeExecutionItem) error {
+	now := time.Now().UTC()
+	exec.EndTime = &now
+	exec.Status = status
+	exec.CandidatesFound = len(items)
+	for _, item := range items {
+		if item.Success {
+			exec.Succeeded++
+		} else {
+			exec.Failed++
+		}
+	}
+
+	if err := db.Save(exec).Error; err != nil {
+		log.Errorf("Failed to complete purge execution %d: %v", exec.Id, err)
+		return err
+	}
+
+	for i := range items {
+		items[i].ExecutionId = exec.Id
+		if err := db.Create(&items[i]).Error; err != nil {
+			log.Errorf("Failed to save purge execution item for campaign %d: %v", items[i].CampaignId, err)
+		}
+	}
+	return nil
+}
+
+// AppendPurgeLogLine tees a log line into an execution's details buffer so
+// GetPurgeExecutionLog can return the combined log for a single run without
+// needing to scrape process stdout.
+func AppendPurgeLogLine(exec *PurgeExecution, line string) {
+	lines := []string{}
+	if exec.Details != "" {
+		_ = json.Unmarshal([]byte(exec.Details), &lines)
+	}
+	lines = append(lines, line)
+	encoded, err := json.Marshal(lines)
+	if err != nil {
+		return
+	}
+	exec.Details = string(encoded)
+	if err := db.Save(exec).Error; err != nil {
+		log.Warnf("Failed to append purge execution log line: %v", err)
+	}
+}
+
+// GetPurgeExecutionLog returns the combined log lines teed into an
+// execution's details buffer.
+func GetPurgeExecutionLog(executionID int64) ([]string, error) {
+	exec, err := GetPurgeExecution(executionID)
+	if err != nil {
+		return nil, err
+	}
+	lines := []string{}
+	if exec.Details != "" {
+		if err := json.Unmarshal([]byte(exec.Details), &lines); err != nil {
+			log.Warn(err)
+		}
+	}
+	return lines, nil
+}
+
+// GetPurgeExecution returns a single execution by ID.
+func GetPurgeExecution(id int64) (*PurgeExecution, error) {
+	exec := &PurgeExecution{}
+	err := db.First(exec, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return exec, nil
+}
+
+// GetPurgeExecutionsPaginated lists purge executions, optionally filtered by
+// status, newest first.
+func GetPurgeExecutionsPaginated(status string, offset, limit int) ([]PurgeExecution, int64, error) {
+	execs := []PurgeExecution{}
+	var total int64
+
+	query := db.Model(&PurgeExecution{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("start_time DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&execs).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return execs, total, nil
+}
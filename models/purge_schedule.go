@@ -0,0 +1,114 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	log "github.com/gophish/gophish/logger"
+	"github.com/jinzhu/gorm"
+)
+
+// PurgeSchedule type values.
+const (
+	ScheduleTypeManual    = "Manual"
+	ScheduleTypeScheduled = "Scheduled"
+	ScheduleTypeNone      = "None"
+)
+
+// PurgeScheduleKindTrash identifies the singleton PurgeSchedule row that
+// governs the campaign-trash TTL job.
+const PurgeScheduleKindTrash = "trash_ttl"
+
+// PurgeScheduleKindAuditLog identifies the singleton PurgeSchedule row that
+// governs the audit-log retention job.
+const PurgeScheduleKindAuditLog = "audit_log"
+
+// PurgeSchedule persists the runtime-configurable schedule for a purge job
+// (e.g. the campaign-trash TTL job), replacing the old behavior of baking
+// the interval/retention into process startup flags. Parameters is a
+// free-form JSON map so each job kind can store its own knobs
+// (retention_days, batch_size, dry_run, ...) without new columns.
+type PurgeSchedule struct {
+	Id         int64     `json:"id" gorm:"primaryKey"`
+	Kind       string    `json:"kind" gorm:"unique_index"` // e.g. trash_ttl, audit_log
+	Type       string    `json:"type"`                     // Manual, Scheduled, None
+	CronExpr   string    `json:"cron_expr,omitempty"`
+	Parameters string    `json:"parameters,omitempty" gorm:"type:text"` // JSON map
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for PurgeSchedule
+func (PurgeSchedule) TableName() string {
+	return "purge_schedules"
+}
+
+// SetParameters serializes a parameters map to JSON
+func (s *PurgeSchedule) SetParameters(params map[string]interface{}) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	s.Parameters = string(data)
+	return nil
+}
+
+// GetParameters deserializes the stored parameters JSON into a map
+func (s *PurgeSchedule) GetParameters() (map[string]interface{}, error) {
+	if s.Parameters == "" {
+		return map[string]interface{}{}, nil
+	}
+	params := map[string]interface{}{}
+	err := json.Unmarshal([]byte(s.Parameters), &params)
+	return params, err
+}
+
+// GetPurgeSchedule returns the persisted schedule for a job kind, or
+// ErrRecordNotFound if one hasn't been configured yet.
+func GetPurgeSchedule(kind string) (*PurgeSchedule, error) {
+	if db == nil {
+		return nil, errors.New("database not initialized")
+	}
+	s := &PurgeSchedule{}
+	err := db.Where("kind = ?", kind).First(s).Error
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// SavePurgeSchedule creates or updates the singleton schedule row for a job
+// kind.
+func SavePurgeSchedule(kind, scheduleType, cronExpr string, params map[string]interface{}) (*PurgeSchedule, error) {
+	if db == nil {
+		return nil, errors.New("database not initialized")
+	}
+
+	s, err := GetPurgeSchedule(kind)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		s = &PurgeSchedule{Kind: kind}
+	}
+
+	s.Type = scheduleType
+	s.CronExpr = cronExpr
+	if err := s.SetParameters(params); err != nil {
+		return nil, err
+	}
+	s.UpdatedAt = time.Now().UTC()
+
+	if err := db.Save(s).Error; err != nil {
+		log.Errorf("Failed to save purge schedule %s: %v", kind, err)
+		return nil, err
+	}
+	return s, nil
+}
+
+// DeletePurgeSchedule removes a job kind's persisted schedule, reverting it
+// to the job's compiled-in defaults; a running job picks that up on its next
+// Reload.
+func DeletePurgeSchedule(kind string) error {
+	return db.Where("kind = ?", kind).Delete(&PurgeSchedule{}).Error
+}
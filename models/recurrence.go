@@ -0,0 +1,120 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var rruleWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// maxRecurrenceInstances caps how many occurrences we'll ever expand for a
+// single campaign, so a malformed or unbounded RRULE can't enqueue an
+// unbounded number of maillogs.
+const maxRecurrenceInstances = 366
+
+// expandRecurrence walks an RFC 5545 RRULE (FREQ=DAILY|WEEKLY|MONTHLY, with
+// optional BYDAY, COUNT, and UNTIL) starting from dtstart and returns each
+// occurrence, skipping any date present in exceptionDates. It only supports
+// the subset of RRULE needed to schedule follow-up campaign mail logs;
+// clients still expand the RRULE line in the ICS itself for display.
+func expandRecurrence(rrule string, dtstart time.Time, exceptionDates []time.Time) ([]time.Time, error) {
+	params := map[string]string{}
+	for _, part := range strings.Split(rrule, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		params[strings.ToUpper(kv[0])] = kv[1]
+	}
+
+	freq := params["FREQ"]
+	count := maxRecurrenceInstances
+	if c, ok := params["COUNT"]; ok {
+		if parsed, err := strconv.Atoi(c); err == nil && parsed > 0 && parsed < count {
+			count = parsed
+		}
+	}
+
+	var until time.Time
+	if u, ok := params["UNTIL"]; ok {
+		parsed, err := time.Parse("20060102T150405Z", u)
+		if err != nil {
+			return nil, fmt.Errorf("invalid UNTIL %q: %w", u, err)
+		}
+		until = parsed
+	}
+
+	excluded := map[string]bool{}
+	for _, d := range exceptionDates {
+		excluded[d.UTC().Format("20060102")] = true
+	}
+
+	var step func(time.Time) time.Time
+	switch freq {
+	case "DAILY":
+		step = func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+	case "WEEKLY":
+		byday := parseByDay(params["BYDAY"])
+		if len(byday) == 0 {
+			step = func(t time.Time) time.Time { return t.AddDate(0, 0, 7) }
+		} else {
+			step = weeklyByDayStep(byday)
+		}
+	case "MONTHLY":
+		step = func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }
+	default:
+		return nil, fmt.Errorf("unsupported FREQ %q", freq)
+	}
+
+	occurrences := make([]time.Time, 0, count)
+	current := dtstart
+	for len(occurrences) < count {
+		if !until.IsZero() && current.After(until) {
+			break
+		}
+		if !excluded[current.UTC().Format("20060102")] {
+			occurrences = append(occurrences, current)
+		}
+		current = step(current)
+	}
+	return occurrences, nil
+}
+
+func parseByDay(byday string) []time.Weekday {
+	if byday == "" {
+		return nil
+	}
+	var days []time.Weekday
+	for _, token := range strings.Split(byday, ",") {
+		if wd, ok := rruleWeekdays[strings.ToUpper(strings.TrimSpace(token))]; ok {
+			days = append(days, wd)
+		}
+	}
+	return days
+}
+
+// weeklyByDayStep returns a step function that advances to the next weekday
+// in byday (wrapping to the following week once all of them have occurred).
+func weeklyByDayStep(byday []time.Weekday) func(time.Time) time.Time {
+	return func(t time.Time) time.Time {
+		for offset := 1; offset <= 7; offset++ {
+			candidate := t.AddDate(0, 0, offset)
+			for _, wd := range byday {
+				if candidate.Weekday() == wd {
+					return candidate
+				}
+			}
+		}
+		return t.AddDate(0, 0, 7)
+	}
+}
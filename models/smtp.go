@@ -0,0 +1,78 @@
+package models
+
+import (
+	"crypto/tls"
+	"net"
+	"time"
+
+	"github.com/gophish/gomail"
+	"github.com/gophish/gophish/mailer"
+)
+
+// Header is a raw key/value SMTP header that gets attached to every message
+// sent through a given SMTP profile (e.g. a custom X-Mailer override).
+type Header struct {
+	Id     int64  `json:"-" gorm:"primaryKey"`
+	SMTPId int64  `json:"-"`
+	Key    string `json:"header"`
+	Value  string `json:"value"`
+}
+
+// SMTP contains the configuration for the relay a campaign sends its email
+// through, along with the per-profile sending behavior (retry/backoff
+// ceiling and outbound rate limit) that MailLog.Backoff and
+// GetQueuedMailLogs consult so a slow or greylisting relay doesn't starve
+// other campaigns sharing the worker's send loop.
+type SMTP struct {
+	Id               int64     `json:"id" gorm:"primaryKey"`
+	UserId           int64     `json:"-"`
+	InterfaceType    string    `json:"interface_type"`
+	Name             string    `json:"name"`
+	Host             string    `json:"host"`
+	Username         string    `json:"username,omitempty"`
+	Password         string    `json:"password,omitempty"`
+	FromAddress      string    `json:"from_address"`
+	IgnoreCertErrors bool      `json:"ignore_cert_errors"`
+	Headers          []Header  `json:"headers" gorm:"-"`
+	ModifyDate       time.Time `json:"modified_date"`
+
+	// MaxSendAttempts overrides the package-level MaxSendAttempts fallback
+	// for campaigns using this profile. Zero means "use the fallback".
+	MaxSendAttempts int `json:"max_send_attempts,omitempty"`
+	// BackoffBase overrides defaultBackoffBase, the minimum decorrelated
+	// jitter delay applied after a transient send failure. Zero means "use
+	// the default".
+	BackoffBase time.Duration `json:"backoff_base,omitempty"`
+	// BackoffCap overrides defaultBackoffCap, the maximum delay a single
+	// backoff step can jump to. Zero means "use the default".
+	BackoffCap time.Duration `json:"backoff_cap,omitempty"`
+	// RateLimitPerSecond caps how many messages per second may be sent
+	// through this profile; the limit is shared by every campaign using it.
+	// Zero (or negative) means the default of defaultSMTPRateLimit (10/sec).
+	RateLimitPerSecond float64 `json:"rate_limit_per_second,omitempty"`
+}
+
+// TableName specifies the table name for SMTP
+func (SMTP) TableName() string {
+	return "smtp"
+}
+
+// GetDialer returns a dialer that can be used to send mail through this
+// SMTP profile.
+func (s *SMTP) GetDialer() (mailer.Dialer, error) {
+	host, portStr, err := net.SplitHostPort(s.Host)
+	if err != nil {
+		host = s.Host
+		portStr = "25"
+	}
+	port := 25
+	if p, err := net.LookupPort("tcp", portStr); err == nil {
+		port = p
+	}
+	dialer := gomail.NewDialer(host, port, s.Username, s.Password)
+	dialer.TLSConfig = &tls.Config{
+		InsecureSkipVerify: s.IgnoreCertErrors,
+		ServerName:         host,
+	}
+	return dialer, nil
+}
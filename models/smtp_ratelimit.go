@@ -0,0 +1,86 @@
+package models
+
+import (
+	"sync"
+	"time"
+)
+
+// tokenBucket is a simple token-bucket rate limiter keyed by SMTP profile
+// ID, so a slow or greylisting relay can't starve other campaigns that share
+// the worker's send loop. capacity and refillRate are deliberately separate:
+// capacity bounds how much unspent burst a caller that checks the bucket
+// infrequently can spend in one go, while refillRate is what the bucket
+// averages out to over time.
+type tokenBucket struct {
+	mu         sync.Mutex
+	tokens     float64
+	capacity   float64
+	refillRate float64 // tokens per second
+	lastRefill time.Time
+}
+
+func newTokenBucket(capacity, refillRate float64) *tokenBucket {
+	return &tokenBucket{
+		tokens:     capacity,
+		capacity:   capacity,
+		refillRate: refillRate,
+		lastRefill: time.Now(),
+	}
+}
+
+// Allow reports whether a token is available and, if so, consumes it.
+func (b *tokenBucket) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	elapsed := now.Sub(b.lastRefill).Seconds()
+	b.lastRefill = now
+	b.tokens += elapsed * b.refillRate
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+var (
+	smtpLimitersMu sync.Mutex
+	smtpLimiters   = map[int64]*tokenBucket{}
+)
+
+// defaultSMTPRateLimit is used for SMTP profiles that don't configure their
+// own send rate.
+const defaultSMTPRateLimit = 10 // messages/sec
+
+// burstWindowSeconds sets the bucket's capacity relative to its refill rate.
+// GetQueuedMailLogs only checks the bucket once per minute, so a capacity
+// equal to the raw per-second rate would cap every pass at "rate" messages
+// instead of the "rate * elapsed seconds" the profile is actually configured
+// for - throttling it to roughly rate/60 of its intended throughput. Sizing
+// capacity to a full minute of refill lets a pass spend everything that
+// accrued since the last one while still averaging out to rateLimit/sec.
+const burstWindowSeconds = 60
+
+// allowSMTPSend consults (creating if necessary) the token bucket for the
+// given SMTP profile, so GetQueuedMailLogs can skip maillogs belonging to a
+// profile that is currently over its configured send rate.
+func allowSMTPSend(smtpID int64, rateLimit float64) bool {
+	if rateLimit <= 0 {
+		rateLimit = defaultSMTPRateLimit
+	}
+
+	smtpLimitersMu.Lock()
+	limiter, ok := smtpLimiters[smtpID]
+	if !ok {
+		limiter = newTokenBucket(rateLimit*burstWindowSeconds, rateLimit)
+		smtpLimiters[smtpID] = limiter
+	}
+	smtpLimitersMu.Unlock()
+
+	return limiter.Allow()
+}
@@ -0,0 +1,282 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/gophish/gophish/logger"
+	"github.com/gophish/gophish/models"
+	"github.com/robfig/cron/v3"
+)
+
+// AuditPurgeJob trims models.AuditLog (and, if configured, the campaign
+// Event log) so audit history doesn't grow unbounded. Like TrashTTLJob, its
+// cadence and parameters are governed by a persisted models.PurgeSchedule
+// (kind=audit_log) that can be changed live through the API.
+type AuditPurgeJob struct {
+	retentionHours  int
+	interval        time.Duration
+	includeEventLog bool
+	enabled         bool
+	stopChan        chan struct{}
+	stopOnce        sync.Once
+	reloadChan      chan struct{}
+	cron            *cron.Cron
+}
+
+// AuditPurgeConfig configures the audit purge job.
+type AuditPurgeConfig struct {
+	RetentionHours  int           // Hours to keep audit log rows (default 180 days)
+	Interval        time.Duration // How often to check, absent a persisted schedule
+	IncludeEventLog bool          // Whether to also purge campaign Event rows
+	Enabled         bool
+}
+
+const defaultAuditRetentionHours = 180 * 24
+
+// NewAuditPurgeJob creates a new audit purge job instance.
+func NewAuditPurgeJob(config AuditPurgeConfig) *AuditPurgeJob {
+	if config.RetentionHours <= 0 {
+		config.RetentionHours = defaultAuditRetentionHours
+	}
+	if config.Interval <= 0 {
+		config.Interval = 24 * time.Hour
+	}
+
+	return &AuditPurgeJob{
+		retentionHours:  config.RetentionHours,
+		interval:        config.Interval,
+		includeEventLog: config.IncludeEventLog,
+		enabled:         config.Enabled,
+		stopChan:        make(chan struct{}),
+		reloadChan:      make(chan struct{}, 1),
+	}
+}
+
+// Reload signals a running job to re-read its persisted PurgeSchedule.
+func (j *AuditPurgeJob) Reload() {
+	select {
+	case j.reloadChan <- struct{}{}:
+	default:
+	}
+}
+
+// Start begins the audit purge job in a goroutine.
+func (j *AuditPurgeJob) Start(ctx context.Context) {
+	if !j.enabled {
+		log.Info("Audit purge job is disabled, not starting")
+		return
+	}
+
+	log.Infof("Starting audit purge job (retention=%dh, interval=%v, include_event_log=%v)",
+		j.retentionHours, j.interval, j.includeEventLog)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				log.Info("Audit purge job stopped (context canceled)")
+				j.stopOnce.Do(func() { close(j.stopChan) })
+				return
+			case <-j.stopChan:
+				log.Info("Audit purge job stopped (stop signal)")
+				return
+			default:
+			}
+
+			j.runWithSchedule(ctx)
+			log.Info("Audit purge job: schedule reload requested, re-reading persisted schedule")
+		}
+	}()
+}
+
+func (j *AuditPurgeJob) runWithSchedule(ctx context.Context) {
+	schedule, err := models.GetPurgeSchedule(models.PurgeScheduleKindAuditLog)
+	if err != nil {
+		j.runFixedInterval(ctx)
+		return
+	}
+
+	if schedule.Type == models.ScheduleTypeNone || schedule.Type == models.ScheduleTypeManual {
+		log.Infof("Audit purge job: schedule type is %s, staying idle", schedule.Type)
+		j.waitForStopOrReload(ctx)
+		return
+	}
+
+	c := cron.New()
+	j.cron = c
+	_, err = c.AddFunc(schedule.CronExpr, func() {
+		runCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+		defer cancel()
+		if err := j.RunOnce(runCtx); err != nil {
+			log.Errorf("Audit purge run failed: %v", err)
+		}
+	})
+	if err != nil {
+		log.Errorf("Audit purge job: invalid cron expression %q, falling back to fixed interval: %v", schedule.CronExpr, err)
+		j.runFixedInterval(ctx)
+		return
+	}
+	c.Start()
+	defer c.Stop()
+
+	j.waitForStopOrReload(ctx)
+}
+
+func (j *AuditPurgeJob) runFixedInterval(ctx context.Context) {
+	runCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	if err := j.RunOnce(runCtx); err != nil {
+		log.Errorf("Initial audit purge run failed: %v", err)
+	}
+	cancel()
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			runCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+			if err := j.RunOnce(runCtx); err != nil {
+				log.Errorf("Audit purge run failed: %v", err)
+			}
+			cancel()
+		case <-ctx.Done():
+			return
+		case <-j.stopChan:
+			return
+		case <-j.reloadChan:
+			return
+		}
+	}
+}
+
+func (j *AuditPurgeJob) waitForStopOrReload(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+	case <-j.stopChan:
+	case <-j.reloadChan:
+	}
+}
+
+// Stop gracefully stops the audit purge job. Safe to call even if the
+// run loop already closed stopChan itself on context cancellation.
+func (j *AuditPurgeJob) Stop() {
+	log.Info("Stopping audit purge job...")
+	if j.cron != nil {
+		j.cron.Stop()
+	}
+	j.stopOnce.Do(func() { close(j.stopChan) })
+}
+
+func (j *AuditPurgeJob) effectiveParameters() (retentionHours int, includeEventLog, dryRun bool) {
+	retentionHours = j.retentionHours
+	includeEventLog = j.includeEventLog
+	dryRun = false
+
+	schedule, err := models.GetPurgeSchedule(models.PurgeScheduleKindAuditLog)
+	if err != nil {
+		return
+	}
+	params, err := schedule.GetParameters()
+	if err != nil {
+		return
+	}
+	if v, ok := params["retention_hours"].(float64); ok && v > 0 {
+		retentionHours = int(v)
+	}
+	if v, ok := params["include_event_log"].(bool); ok {
+		includeEventLog = v
+	}
+	if v, ok := params["dry_run"].(bool); ok {
+		dryRun = v
+	}
+	return
+}
+
+// RunOnce executes a single audit purge cycle.
+func (j *AuditPurgeJob) RunOnce(ctx context.Context) error {
+	return j.runOnce(ctx, models.PurgeTriggerScheduled)
+}
+
+// TriggerManual creates an AuditPurgeExecution record and runs the purge
+// asynchronously, returning the execution ID immediately.
+func (j *AuditPurgeJob) TriggerManual() (int64, error) {
+	retentionHours, includeEventLog, dryRun := j.effectiveParameters()
+	cutoff := time.Now().Add(-time.Duration(retentionHours) * time.Hour)
+
+	exec, err := models.CreateAuditPurgeExecution(models.PurgeTriggerManual, cutoff, dryRun, includeEventLog)
+	if err != nil {
+		return 0, err
+	}
+
+	go func() {
+		runCtx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		if err := j.runExecution(runCtx, cutoff, includeEventLog, dryRun, exec); err != nil {
+			log.Errorf("Manual audit purge run failed: %v", err)
+		}
+	}()
+
+	return exec.Id, nil
+}
+
+func (j *AuditPurgeJob) runOnce(ctx context.Context, trigger string) error {
+	retentionHours, includeEventLog, dryRun := j.effectiveParameters()
+	cutoff := time.Now().Add(-time.Duration(retentionHours) * time.Hour)
+
+	exec, execErr := models.CreateAuditPurgeExecution(trigger, cutoff, dryRun, includeEventLog)
+	if execErr != nil {
+		log.Errorf("Audit purge: failed to create execution record: %v", execErr)
+	}
+	return j.runExecution(ctx, cutoff, includeEventLog, dryRun, exec)
+}
+
+func (j *AuditPurgeJob) runExecution(ctx context.Context, cutoff time.Time, includeEventLog, dryRun bool, exec *models.AuditPurgeExecution) error {
+	select {
+	case <-ctx.Done():
+		if exec != nil {
+			models.CompleteAuditPurgeExecution(exec, models.PurgeStatusStopped, 0, 0)
+		}
+		return ctx.Err()
+	default:
+	}
+
+	auditRows, eventRows, err := models.PurgeAuditLogs(cutoff, includeEventLog, dryRun)
+	if err != nil {
+		if exec != nil {
+			models.CompleteAuditPurgeExecution(exec, models.PurgeStatusError, auditRows, eventRows)
+		}
+		return fmt.Errorf("failed to purge audit logs: %w", err)
+	}
+
+	log.Infof("Audit purge: %d audit row(s), %d event row(s) older than %s (dry_run=%v)",
+		auditRows, eventRows, cutoff.Format(time.RFC3339), dryRun)
+
+	if exec != nil {
+		models.CompleteAuditPurgeExecution(exec, models.PurgeStatusSuccess, auditRows, eventRows)
+	}
+
+	return nil
+}
+
+// GetMetrics returns current job metrics (for observability)
+func (j *AuditPurgeJob) GetMetrics() map[string]interface{} {
+	retentionHours, includeEventLog, dryRun := j.effectiveParameters()
+	metrics := map[string]interface{}{
+		"retention_hours":   retentionHours,
+		"interval":          j.interval.String(),
+		"include_event_log": includeEventLog,
+		"dry_run":           dryRun,
+		"enabled":           j.enabled,
+	}
+
+	if schedule, err := models.GetPurgeSchedule(models.PurgeScheduleKindAuditLog); err == nil {
+		metrics["schedule_type"] = schedule.Type
+		metrics["cron_expr"] = schedule.CronExpr
+	}
+
+	return metrics
+}
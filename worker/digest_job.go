@@ -0,0 +1,169 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	log "github.com/gophish/gophish/logger"
+	"github.com/gophish/gophish/models"
+	"github.com/robfig/cron/v3"
+)
+
+// digestScanInterval bounds how often DigestJob re-lists active digest
+// campaigns and checks whether each is due. It's not itself a send
+// cadence: a campaign only actually runs once its own CronExpr (or, absent
+// one, the job's configured DigestConfig.Interval) says it's due.
+const digestScanInterval = time.Minute
+
+// DigestJob periodically scans every active digest campaign and, for each
+// one that's due, queues a summary MailLog per result via
+// models.GenerateDigestMailLog, the way TrashTTLJob periodically sweeps
+// trashed campaigns. Unlike TrashTTLJob it takes no distributed lock, and
+// its lastRun bookkeeping is per process: two replicas running it each
+// queue their own digest maillog for the same window.
+type DigestJob struct {
+	interval time.Duration
+	enabled  bool
+	stopChan chan struct{}
+	lastRun  map[int64]time.Time
+}
+
+// DigestConfig configures the digest job.
+type DigestConfig struct {
+	Interval time.Duration // Fallback cadence for campaigns with no CronExpr of their own
+	Enabled  bool
+}
+
+// NewDigestJob creates a new digest job instance.
+func NewDigestJob(config DigestConfig) *DigestJob {
+	if config.Interval <= 0 {
+		config.Interval = 15 * time.Minute
+	}
+
+	return &DigestJob{
+		interval: config.Interval,
+		enabled:  config.Enabled,
+		stopChan: make(chan struct{}),
+		lastRun:  make(map[int64]time.Time),
+	}
+}
+
+// Start begins the digest job in a goroutine.
+func (j *DigestJob) Start(ctx context.Context) {
+	if !j.enabled {
+		log.Info("Digest job is disabled, not starting")
+		return
+	}
+
+	log.Infof("Starting digest job (scan interval=%v, default cadence=%v)", digestScanInterval, j.interval)
+
+	go func() {
+		j.RunOnce(ctx)
+
+		ticker := time.NewTicker(digestScanInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				j.RunOnce(ctx)
+			case <-ctx.Done():
+				log.Info("Digest job stopped (context canceled)")
+				return
+			case <-j.stopChan:
+				log.Info("Digest job stopped (stop signal)")
+				return
+			}
+		}
+	}()
+}
+
+// Stop gracefully stops the digest job.
+func (j *DigestJob) Stop() {
+	log.Info("Stopping digest job...")
+	close(j.stopChan)
+}
+
+// RunOnce scans every active digest campaign and, for each one that's due
+// per its own CronExpr (or the job's default cadence, absent one), queues a
+// digest maillog for each of its results. Errors for an individual campaign
+// or result are logged and skipped so one bad record doesn't stall the rest
+// of the pass.
+func (j *DigestJob) RunOnce(ctx context.Context) {
+	refs, err := models.ListActiveDigestCampaigns()
+	if err != nil {
+		log.Errorf("Digest job: failed to list active digest campaigns: %v", err)
+		return
+	}
+
+	now := time.Now()
+
+	for _, ref := range refs {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		windowStart, due := j.dueSince(ref, now)
+		if !due {
+			continue
+		}
+
+		c, err := models.GetCampaignMailContext(ref.ID, ref.UserID)
+		if err != nil {
+			log.Errorf("Digest job: failed to load campaign %d: %v", ref.ID, err)
+			continue
+		}
+
+		rids, err := models.ResultRIDsForCampaign(ref.ID)
+		if err != nil {
+			log.Errorf("Digest job: failed to list results for campaign %d: %v", ref.ID, err)
+			continue
+		}
+
+		for _, rid := range rids {
+			r, err := models.GetResult(rid)
+			if err != nil {
+				log.Errorf("Digest job: failed to load result %s: %v", rid, err)
+				continue
+			}
+			if err := models.GenerateDigestMailLog(&c, &r, windowStart); err != nil {
+				log.Errorf("Digest job: failed to generate digest maillog for result %s: %v", rid, err)
+			}
+		}
+
+		j.lastRun[ref.ID] = now
+	}
+}
+
+// dueSince reports whether ref's digest is due to run at now, and if so the
+// window its items should be collected since. A campaign with its own valid
+// CronExpr is due once that schedule's next fire time (computed from its
+// last run) has passed; otherwise it falls back to the job's fixed
+// interval, matching the job's old fixed-cadence behavior.
+func (j *DigestJob) dueSince(ref models.DigestCampaignRef, now time.Time) (time.Time, bool) {
+	last, ok := j.lastRun[ref.ID]
+	if !ok {
+		last = now.Add(-j.interval)
+	}
+
+	if ref.CronExpr != "" {
+		schedule, err := cron.ParseStandard(ref.CronExpr)
+		if err != nil {
+			log.Errorf("Digest job: campaign %d has invalid cron expression %q, falling back to default cadence: %v", ref.ID, ref.CronExpr, err)
+		} else {
+			return last, !schedule.Next(last).After(now)
+		}
+	}
+
+	return last, now.Sub(last) >= j.interval
+}
+
+// GetMetrics returns current job metrics (for observability)
+func (j *DigestJob) GetMetrics() map[string]interface{} {
+	return map[string]interface{}{
+		"interval": j.interval.String(),
+		"enabled":  j.enabled,
+	}
+}
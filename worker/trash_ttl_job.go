@@ -3,19 +3,46 @@ package worker
 import (
 	"context"
 	"fmt"
+	"os"
+	"sync"
 	"time"
 
 	log "github.com/gophish/gophish/logger"
 	"github.com/gophish/gophish/models"
+	"github.com/robfig/cron/v3"
 )
 
-// TrashTTLJob handles automatic purging of campaigns after retention period
+// systemActorName identifies the TTL job as the actor in audit_log rows its
+// bulk purges write, mirroring PurgeSystemCampaign's ActorName convention.
+const systemActorName = "system:trash-ttl"
+
+// trashTTLLockName identifies the distributed lock guarding TrashTTLJob runs
+// so two replicas behind a load balancer don't race on the same purge
+// candidates.
+const trashTTLLockName = "trash-ttl"
+
+// TrashTTLJob handles automatic purging of campaigns after retention period.
+// The retention/interval/batch defaults below are used until an admin
+// persists a models.PurgeSchedule (kind=trash_ttl) through the API; once one
+// exists, it takes over and can be changed live without a restart.
 type TrashTTLJob struct {
 	retentionDays int
 	interval      time.Duration
 	batchSize     int
 	enabled       bool
 	stopChan      chan struct{}
+	reloadChan    chan struct{}
+	cron          *cron.Cron
+	// owner identifies this process in the locks table, so a run this
+	// process acquired can be told apart from one held by another replica.
+	owner string
+
+	// throughputMu guards the fields below, which GetMetrics reports and
+	// runExecution updates after every run so observability doesn't have to
+	// wait on a PurgeExecution query.
+	throughputMu        sync.Mutex
+	lastRowsPerSecond   float64
+	lastChunksCommitted int
 }
 
 // TrashTTLConfig configures the TTL job
@@ -45,10 +72,36 @@ func NewTrashTTLJob(config TrashTTLConfig) *TrashTTLJob {
 		batchSize:     config.BatchSize,
 		enabled:       config.Enabled,
 		stopChan:      make(chan struct{}),
+		reloadChan:    make(chan struct{}, 1),
+		owner:         lockOwner(),
 	}
 }
 
-// Start begins the TTL job in a goroutine
+// lockOwner returns a per-process identity for the trash-ttl lock record,
+// so GetMetrics and log lines can tell which replica currently holds it.
+func lockOwner() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+// Reload signals a running job to re-read its persisted PurgeSchedule,
+// picking up a cron expression or parameter change made through the API
+// without requiring a restart.
+func (j *TrashTTLJob) Reload() {
+	select {
+	case j.reloadChan <- struct{}{}:
+	default:
+	}
+}
+
+// Start begins the TTL job in a goroutine. If a models.PurgeSchedule
+// (kind=trash_ttl) has been persisted, its Type/CronExpr/Parameters govern
+// the run cadence; otherwise the compiled-in TrashTTLConfig defaults apply.
+// A schedule with Type=None keeps the job registered but idle, so admins can
+// re-enable it later without a restart.
 func (j *TrashTTLJob) Start(ctx context.Context) {
 	if !j.enabled {
 		log.Info("Trash TTL job is disabled, not starting")
@@ -58,103 +111,327 @@ func (j *TrashTTLJob) Start(ctx context.Context) {
 	log.Infof("Starting Trash TTL job (retention=%d days, interval=%v, batch=%d)",
 		j.retentionDays, j.interval, j.batchSize)
 
-	// Run immediately on startup
 	go func() {
-		// Initial run with timeout
+		for {
+			select {
+			case <-ctx.Done():
+				log.Info("Trash TTL job stopped (context canceled)")
+				close(j.stopChan)
+				return
+			case <-j.stopChan:
+				log.Info("Trash TTL job stopped (stop signal)")
+				return
+			default:
+			}
+
+			j.runWithSchedule(ctx)
+			log.Info("Trash TTL job: schedule reload requested, re-reading persisted schedule")
+		}
+	}()
+}
+
+// runWithSchedule runs a cron.Cron configured from the persisted
+// PurgeSchedule (or the legacy fixed-interval ticker if none is configured)
+// until the job is stopped or asked to reload.
+func (j *TrashTTLJob) runWithSchedule(ctx context.Context) {
+	schedule, err := models.GetPurgeSchedule(models.PurgeScheduleKindTrash)
+	if err != nil {
+		// No persisted schedule yet - fall back to the fixed interval this
+		// job was constructed with.
+		j.runFixedInterval(ctx)
+		return
+	}
+
+	if schedule.Type == models.ScheduleTypeNone {
+		log.Info("Trash TTL job: schedule type is None, staying idle")
+		j.waitForStopOrReload(ctx)
+		return
+	}
+
+	if schedule.Type == models.ScheduleTypeManual {
+		log.Info("Trash TTL job: schedule type is Manual, only running via explicit trigger")
+		j.waitForStopOrReload(ctx)
+		return
+	}
+
+	c := cron.New()
+	j.cron = c
+	_, err = c.AddFunc(schedule.CronExpr, func() {
 		runCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
-		if err := j.RunOnce(runCtx); err != nil {
+		defer cancel()
+		j.runLocked(runCtx, func(ctx context.Context) {
+			if err := j.RunOnce(ctx); err != nil {
+				log.Errorf("Trash TTL run failed: %v", err)
+			}
+		})
+	})
+	if err != nil {
+		log.Errorf("Trash TTL job: invalid cron expression %q, falling back to fixed interval: %v", schedule.CronExpr, err)
+		j.runFixedInterval(ctx)
+		return
+	}
+	c.Start()
+	defer c.Stop()
+
+	j.waitForStopOrReload(ctx)
+}
+
+// runFixedInterval is the original ticker-based loop, used when no
+// PurgeSchedule has been persisted yet.
+func (j *TrashTTLJob) runFixedInterval(ctx context.Context) {
+	runCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	j.runLocked(runCtx, func(ctx context.Context) {
+		if err := j.RunOnce(ctx); err != nil {
 			log.Errorf("Initial trash TTL run failed: %v", err)
 		}
-		cancel()
+	})
+	cancel()
 
-		ticker := time.NewTicker(j.interval)
-		defer ticker.Stop()
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
 
-		for {
-			select {
-			case <-ticker.C:
-				// Run with timeout to prevent infinite hangs
-				runCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
-				if err := j.RunOnce(runCtx); err != nil {
+	for {
+		select {
+		case <-ticker.C:
+			runCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+			j.runLocked(runCtx, func(ctx context.Context) {
+				if err := j.RunOnce(ctx); err != nil {
 					log.Errorf("Trash TTL run failed: %v", err)
 				}
-				cancel()
+			})
+			cancel()
+		case <-ctx.Done():
+			return
+		case <-j.stopChan:
+			return
+		case <-j.reloadChan:
+			return
+		}
+	}
+}
 
-			case <-ctx.Done():
-				log.Info("Trash TTL job stopped (context canceled)")
-				close(j.stopChan)
-				return
+// runLocked attempts to acquire the trash-ttl lock before running fn, so
+// two replicas behind a load balancer don't race on the same purge
+// candidates. If the lock isn't acquired (another replica is mid-run), fn
+// is skipped for this tick. While fn runs, a renewer goroutine refreshes
+// the lease every interval/3 so a slow batch doesn't let the lease expire
+// and another replica steal it mid-run.
+func (j *TrashTTLJob) runLocked(ctx context.Context, fn func(ctx context.Context)) {
+	ttl := 2 * j.interval
+	acquired, err := models.AcquireLock(trashTTLLockName, ttl, j.owner)
+	if err != nil {
+		log.Errorf("Trash TTL: failed to acquire lock: %v", err)
+		return
+	}
+	if !acquired {
+		log.Debug("Trash TTL: lock held by another replica, skipping this run")
+		return
+	}
+	defer func() {
+		if err := models.ReleaseLock(trashTTLLockName, j.owner); err != nil {
+			log.Errorf("Trash TTL: failed to release lock: %v", err)
+		}
+	}()
 
-			case <-j.stopChan:
-				log.Info("Trash TTL job stopped (stop signal)")
+	renewCtx, cancelRenew := context.WithCancel(ctx)
+	defer cancelRenew()
+
+	renewInterval := j.interval / 3
+	if renewInterval <= 0 {
+		renewInterval = time.Second
+	}
+	go func() {
+		ticker := time.NewTicker(renewInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-renewCtx.Done():
 				return
+			case <-ticker.C:
+				if _, err := models.RenewLock(trashTTLLockName, ttl, j.owner); err != nil {
+					log.Errorf("Trash TTL: failed to renew lock: %v", err)
+				}
 			}
 		}
 	}()
+
+	fn(ctx)
+}
+
+func (j *TrashTTLJob) waitForStopOrReload(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+	case <-j.stopChan:
+	case <-j.reloadChan:
+	}
 }
 
 // Stop gracefully stops the TTL job
 func (j *TrashTTLJob) Stop() {
 	log.Info("Stopping Trash TTL job...")
+	if j.cron != nil {
+		j.cron.Stop()
+	}
 	close(j.stopChan)
 }
 
+// effectiveRetentionDays returns retention_days from the persisted
+// PurgeSchedule's Parameters, falling back to the value the job was
+// constructed with, so an admin can change retention (e.g. 90 -> 30 days)
+// live without a restart.
+func (j *TrashTTLJob) effectiveRetentionDays() int {
+	schedule, err := models.GetPurgeSchedule(models.PurgeScheduleKindTrash)
+	if err != nil {
+		return j.retentionDays
+	}
+	params, err := schedule.GetParameters()
+	if err != nil {
+		return j.retentionDays
+	}
+	if v, ok := params["retention_days"].(float64); ok && v > 0 {
+		return int(v)
+	}
+	return j.retentionDays
+}
+
+// effectiveBatchSize mirrors effectiveRetentionDays for batch_size.
+func (j *TrashTTLJob) effectiveBatchSize() int {
+	schedule, err := models.GetPurgeSchedule(models.PurgeScheduleKindTrash)
+	if err != nil {
+		return j.batchSize
+	}
+	params, err := schedule.GetParameters()
+	if err != nil {
+		return j.batchSize
+	}
+	if v, ok := params["batch_size"].(float64); ok && v > 0 {
+		return int(v)
+	}
+	return j.batchSize
+}
+
 // RunOnce executes a single purge cycle (useful for testing and manual triggers)
 func (j *TrashTTLJob) RunOnce(ctx context.Context) error {
+	return j.runOnce(ctx, models.PurgeTriggerScheduled)
+}
+
+// TriggerManual creates a PurgeExecution record and runs the batch
+// asynchronously, returning the execution ID immediately so an admin-facing
+// "run now" endpoint doesn't block the HTTP request for the duration of the
+// purge.
+func (j *TrashTTLJob) TriggerManual() (int64, error) {
+	cutoff := time.Now().Add(-time.Duration(j.effectiveRetentionDays()) * 24 * time.Hour)
+	exec, err := models.CreatePurgeExecution(models.PurgeTriggerManual, cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	go func() {
+		runCtx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		j.runLocked(runCtx, func(ctx context.Context) {
+			if err := j.runExecution(ctx, cutoff, exec); err != nil {
+				log.Errorf("Manual trash TTL run failed: %v", err)
+			}
+		})
+	}()
+
+	return exec.Id, nil
+}
+
+func (j *TrashTTLJob) runOnce(ctx context.Context, trigger string) error {
+	cutoff := time.Now().Add(-time.Duration(j.effectiveRetentionDays()) * 24 * time.Hour)
+	exec, execErr := models.CreatePurgeExecution(trigger, cutoff)
+	if execErr != nil {
+		log.Errorf("Trash TTL: Failed to create purge execution record: %v", execErr)
+	}
+	return j.runExecution(ctx, cutoff, exec)
+}
+
+func (j *TrashTTLJob) runExecution(ctx context.Context, cutoff time.Time, exec *models.PurgeExecution) error {
 	startTime := time.Now()
-	
-	// Calculate cutoff time
-	cutoff := time.Now().Add(-time.Duration(j.retentionDays) * 24 * time.Hour)
-	
+
 	log.Debugf("Trash TTL: Looking for campaigns deleted before %s", cutoff.Format(time.RFC3339))
+	if exec != nil {
+		models.AppendPurgeLogLine(exec, fmt.Sprintf("looking for campaigns deleted before %s", cutoff.Format(time.RFC3339)))
+	}
 
 	// Get candidates for purge
-	candidateIDs, err := models.ListPurgeCandidates(cutoff, j.batchSize)
+	candidateIDs, err := models.ListPurgeCandidates(cutoff, j.effectiveBatchSize())
 	if err != nil {
+		if exec != nil {
+			models.AppendPurgeLogLine(exec, fmt.Sprintf("failed to list purge candidates: %v", err))
+			models.CompletePurgeExecution(exec, models.PurgeStatusError, nil)
+		}
 		return fmt.Errorf("failed to list purge candidates: %w", err)
 	}
 
 	if len(candidateIDs) == 0 {
 		log.Debug("Trash TTL: No campaigns to purge")
+		if exec != nil {
+			models.AppendPurgeLogLine(exec, "no campaigns to purge")
+			models.CompletePurgeExecution(exec, models.PurgeStatusSuccess, nil)
+		}
 		return nil
 	}
 
 	log.Infof("Trash TTL: Found %d campaign(s) to purge", len(candidateIDs))
+	if exec != nil {
+		models.AppendPurgeLogLine(exec, fmt.Sprintf("found %d campaign(s) to purge", len(candidateIDs)))
+	}
 
-	// Track metrics
-	successCount := 0
-	errorCount := 0
-	skippedCount := 0
-
-	// Process each campaign
-	for i, campaignID := range candidateIDs {
-		// Check for cancellation
-		select {
-		case <-ctx.Done():
-			log.Warnf("Trash TTL: Context canceled after %d/%d campaigns", i, len(candidateIDs))
-			return ctx.Err()
-		default:
+	// Check for cancellation before doing any work - PurgeCampaignsBulk
+	// doesn't itself watch ctx, since a chunk's transaction shouldn't be
+	// interrupted partway through.
+	select {
+	case <-ctx.Done():
+		log.Warnf("Trash TTL: Context canceled before purging %d campaigns", len(candidateIDs))
+		if exec != nil {
+			models.AppendPurgeLogLine(exec, "context canceled before purging")
+			models.CompletePurgeExecution(exec, models.PurgeStatusStopped, nil)
 		}
+		return ctx.Err()
+	default:
+	}
 
-		log.Debugf("Trash TTL: Purging campaign %d (%d/%d)", campaignID, i+1, len(candidateIDs))
-
-		err := models.PurgeSystemCampaign(campaignID)
-		if err != nil {
-			log.Errorf("Trash TTL: Failed to purge campaign %d: %v", campaignID, err)
-			errorCount++
-			// Continue to next campaign (don't fail entire batch)
-			continue
+	result, err := models.PurgeCampaignsBulk(candidateIDs, nil, systemActorName, false)
+	if err != nil {
+		if exec != nil {
+			models.AppendPurgeLogLine(exec, fmt.Sprintf("bulk purge failed: %v", err))
+			models.CompletePurgeExecution(exec, models.PurgeStatusError, nil)
 		}
+		return fmt.Errorf("bulk purge failed: %w", err)
+	}
 
-		// Check if it was a no-op (already purged or restored)
-		// PurgeSystemCampaign returns nil for idempotent cases
-		successCount++
+	items := make([]models.PurgeExecutionItem, 0, len(candidateIDs))
+	for _, id := range result.Succeeded {
+		items = append(items, models.PurgeExecutionItem{CampaignId: id, Success: true})
+	}
+	for id, reason := range result.Skipped {
+		items = append(items, models.PurgeExecutionItem{CampaignId: id, Success: true, ErrorMessage: reason})
+	}
+	for id, err := range result.Failed {
+		items = append(items, models.PurgeExecutionItem{CampaignId: id, Success: false, ErrorMessage: err.Error()})
 	}
 
 	// Log summary
 	duration := time.Since(startTime)
-	log.Infof("Trash TTL: Batch complete - %d succeeded, %d errors, %d skipped in %v",
-		successCount, errorCount, skippedCount, duration)
+	successCount := len(result.Succeeded)
+	errorCount := len(result.Failed)
+	log.Infof("Trash TTL: Batch complete - %d succeeded, %d skipped, %d errors in %v (%d chunk(s) committed)",
+		successCount, len(result.Skipped), errorCount, duration, result.ChunksCommitted)
+
+	j.recordThroughput(successCount, duration, result.ChunksCommitted)
+
+	status := models.PurgeStatusSuccess
+	if errorCount > 0 && successCount == 0 {
+		status = models.PurgeStatusError
+	}
+	if exec != nil {
+		models.AppendPurgeLogLine(exec, fmt.Sprintf("batch complete - %d succeeded, %d skipped, %d errors in %v (%d chunk(s) committed)",
+			successCount, len(result.Skipped), errorCount, duration, result.ChunksCommitted))
+		models.CompletePurgeExecution(exec, status, items)
+	}
 
 	// Return error if entire batch failed
 	if errorCount > 0 && successCount == 0 {
@@ -164,12 +441,42 @@ func (j *TrashTTLJob) RunOnce(ctx context.Context) error {
 	return nil
 }
 
+// recordThroughput stashes the rows/sec and chunk count from the most
+// recent run for GetMetrics to report.
+func (j *TrashTTLJob) recordThroughput(rowsPurged int, duration time.Duration, chunksCommitted int) {
+	j.throughputMu.Lock()
+	defer j.throughputMu.Unlock()
+	if duration > 0 {
+		j.lastRowsPerSecond = float64(rowsPurged) / duration.Seconds()
+	} else {
+		j.lastRowsPerSecond = 0
+	}
+	j.lastChunksCommitted = chunksCommitted
+}
+
 // GetMetrics returns current job metrics (for observability)
 func (j *TrashTTLJob) GetMetrics() map[string]interface{} {
-	return map[string]interface{}{
-		"retention_days": j.retentionDays,
+	metrics := map[string]interface{}{
+		"retention_days": j.effectiveRetentionDays(),
 		"interval":       j.interval.String(),
-		"batch_size":     j.batchSize,
+		"batch_size":     j.effectiveBatchSize(),
 		"enabled":        j.enabled,
 	}
+
+	if schedule, err := models.GetPurgeSchedule(models.PurgeScheduleKindTrash); err == nil {
+		metrics["schedule_type"] = schedule.Type
+		metrics["cron_expr"] = schedule.CronExpr
+	}
+
+	if lock, err := models.GetLock(trashTTLLockName); err == nil {
+		metrics["lock_owner"] = lock.Owner
+		metrics["lock_expires_at"] = lock.ExpiresAt
+	}
+
+	j.throughputMu.Lock()
+	metrics["last_rows_per_second"] = j.lastRowsPerSecond
+	metrics["last_chunks_committed"] = j.lastChunksCommitted
+	j.throughputMu.Unlock()
+
+	return metrics
 }
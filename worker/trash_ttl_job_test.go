@@ -61,6 +61,46 @@ func TestGetMetrics(t *testing.T) {
 	assert.Equal(t, true, metrics["enabled"])
 }
 
+func TestGetMetrics_Throughput(t *testing.T) {
+	job := NewTrashTTLJob(TrashTTLConfig{Enabled: true})
+
+	// Before any run, throughput metrics should report their zero values.
+	metrics := job.GetMetrics()
+	assert.Equal(t, float64(0), metrics["last_rows_per_second"])
+	assert.Equal(t, 0, metrics["last_chunks_committed"])
+
+	job.recordThroughput(100, 2*time.Second, 2)
+
+	metrics = job.GetMetrics()
+	assert.Equal(t, float64(50), metrics["last_rows_per_second"])
+	assert.Equal(t, 2, metrics["last_chunks_committed"])
+}
+
+func TestLockOwner_StableAndNonEmpty(t *testing.T) {
+	job := NewTrashTTLJob(TrashTTLConfig{Enabled: true})
+	assert.NotEmpty(t, job.owner, "lock owner should be derived from hostname+pid")
+
+	other := NewTrashTTLJob(TrashTTLConfig{Enabled: true})
+	assert.Equal(t, job.owner, other.owner, "lock owner should be stable for the same process")
+}
+
+func TestRunLocked_SkipsWhenLockUnavailable(t *testing.T) {
+	job := NewTrashTTLJob(TrashTTLConfig{
+		RetentionDays: 90,
+		Interval:      time.Minute,
+		Enabled:       true,
+	})
+
+	ran := false
+	// Since no database is initialized in this unit test, AcquireLock
+	// returns an error and runLocked must skip fn rather than panic or
+	// run unprotected.
+	job.runLocked(context.Background(), func(ctx context.Context) {
+		ran = true
+	})
+	assert.False(t, ran, "fn should not run when the lock can't be acquired")
+}
+
 func TestRunOnce_ContextCancellation(t *testing.T) {
 	job := NewTrashTTLJob(TrashTTLConfig{
 		RetentionDays: 90,